@@ -0,0 +1,194 @@
+package monogo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brunoluiz/monogo/git"
+	"github.com/brunoluiz/monogo/glob"
+	"github.com/brunoluiz/monogo/walker"
+	"github.com/brunoluiz/monogo/walker/hook"
+	"github.com/samber/lo"
+	"golang.org/x/sync/errgroup"
+)
+
+// Affected detects the entrypoints affected by the given repository relative files, walking the current
+// tree only. It does not use git, so files can't be compared against a base: they are reported as updated,
+// or deleted if missing, and go.mod changes are not analysed (global triggers can be used for that). Deleted
+// Go files mark the package in their directory.
+func (r *Detector) Affected(ctx context.Context, files []string) (DetectRes, error) {
+	res := DetectRes{
+		Reasons:  []ChangeReason{},
+		Triggers: []string{},
+		Mod: DetectModRes{
+			Godebug:      []string{},
+			Tools:        DetectToolsRes{Added: []string{}, Deleted: []string{}},
+			Dependencies: []DetectDependencyRes{},
+		},
+		Git: DetectGitRes{
+			Files: DetectGitChangesRes{
+				Created:  DetectFileTypeRes{Go: []string{}, All: []string{}},
+				Deleted:  DetectFileTypeRes{Go: []string{}, All: []string{}},
+				Updated:  DetectFileTypeRes{Go: []string{}, All: []string{}},
+				Impacted: DetectFileTypeRes{Go: []string{}, All: []string{}},
+				Ignored:  DetectFileTypeRes{Go: []string{}, All: []string{}},
+			},
+		},
+		Stats:       DetectStatsRes{StartedAt: time.Now(), EndedAt: time.Now()},
+		Entrypoints: []DetectEntrypointRes{},
+		Libraries:   []DetectEntrypointRes{},
+		Waves:       [][]string{},
+	}
+
+	changes, err := r.filesDiff(files)
+	if err != nil {
+		return DetectRes{}, err
+	}
+
+	changes, ignored := r.withoutIgnored(changes)
+	r.populateFilesFromChanges(&res.Git.Files, changes)
+	res.Git.Files.Ignored.All = ignored
+	res.Git.Files.Ignored.Go = lo.Filter(ignored, func(file string, _ int) bool { return strings.HasSuffix(file, ".go") })
+
+	all := changes.All()
+	if res.Triggers, _ = glob.Filter(r.Triggers, all); len(res.Triggers) > 0 {
+		res.Reasons = append(res.Reasons, GlobalTriggerReason)
+	}
+
+	w, err := walker.New(r.Path, r.Logger.WithGroup("walker:affected"))
+	if err != nil {
+		return DetectRes{}, err
+	}
+
+	targets, err := r.targets(ctx, w)
+	if err != nil {
+		return DetectRes{}, fmt.Errorf("failed to get entrypoints: %w", err)
+	}
+	for _, entry := range targets {
+		if !w.Exists(entry) {
+			return DetectRes{}, fmt.Errorf("entrypoint %s not found", entry)
+		}
+	}
+
+	inputsByEntrypoint, err := r.inputFiles(r.Path, targets, r.Inputs)
+	if err != nil {
+		return DetectRes{}, err
+	}
+
+	// Runs each entrypoint walker with go routines: you must test it with `-race` enabled
+	results := []DetectEntrypointRes{}
+	eg, egCtx := errgroup.WithContext(ctx)
+	rw := sync.RWMutex{}
+	for _, entry := range targets {
+		entry := entry
+		eg.Go(func() error {
+			ignored, entryChanges := glob.Filter(globsFor(r.EntrypointIgnore, entry), all)
+			changesHook := hook.NewChangeDetector(absPaths(r.Path, entryChanges))
+			listerHook := hook.NewLister()
+			chainsHook := hook.NewChainFinder()
+			hooks := []walker.Hook{changesHook, listerHook}
+			if r.Explain {
+				hooks = append(hooks, chainsHook)
+			}
+			if err := w.Walk(egCtx, entry, hooks...); err != nil {
+				return err
+			}
+
+			reasons := []ChangeReason{}
+			if changesHook.Found() {
+				reasons = append(reasons, ChangedFilesReason)
+			}
+			deleted := packageDeletions(lo.Intersect(entryChanges, changes.Deleted), relPaths(r.Path, listerHook.Files()))
+			if len(deleted) > 0 {
+				reasons = append(reasons, CreatedDeletedFilesReasons)
+			}
+			if len(res.Triggers) > 0 {
+				reasons = append(reasons, GlobalTriggerReason)
+			}
+			reasons = append(reasons, r.inputsReasons(r.Inputs, entry, entryChanges)...)
+
+			// Write operations to shared memory below
+			rw.Lock()
+			defer rw.Unlock()
+			changed := len(reasons) > 0
+			if changed || r.ShowUnchanged {
+				results = append(results, DetectEntrypointRes{
+					Path:         entry,
+					Changed:      changed,
+					Reasons:      reasons,
+					Dependencies: []DetectDependencyRes{},
+					Inputs:       inputsByEntrypoint[entry],
+					Ignored:      ignored,
+					Triggers:     res.Triggers,
+					Evidence: r.evidence(entry, reasons, explainInfo{
+						root:         r.Path,
+						changes:      all,
+						entryChanges: entryChanges,
+						inputs:       r.Inputs,
+						matches:      changesHook.Matches(),
+						chains:       chainsHook,
+						baseFiles:    deleted,
+					}),
+				})
+			}
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return DetectRes{}, fmt.Errorf("failure while walking entrypoints: %w", err)
+	}
+
+	results, res.Waves, err = r.dependsOn(results, targets)
+	if err != nil {
+		return DetectRes{}, err
+	}
+
+	r.populateMetadata(results)
+	res.Entrypoints, res.Libraries = r.splitLibraries(results)
+	res.Stats.end()
+	res.Changed, res.LibrariesChanged = anyChanged(res.Entrypoints), anyChanged(res.Libraries)
+	return res, nil
+}
+
+// packageDeletions returns the deleted Go files within the directory of any walked file, as they were part
+// of its package
+func packageDeletions(deleted, files []string) []string {
+	dirs := lo.Uniq(lo.Map(files, func(file string, _ int) string { return path.Dir(file) }))
+	return lo.Filter(deleted, func(file string, _ int) bool {
+		return isGoFile(file) && slices.Contains(dirs, path.Dir(file))
+	})
+}
+
+// filesDiff maps the files to a diff, as updated files or deleted ones if not found in the tree
+func (r *Detector) filesDiff(files []string) (git.DiffResult, error) {
+	diff := git.DiffResult{Created: []string{}, Updated: []string{}, Deleted: []string{}, Renamed: map[string]string{}}
+	files = lo.Map(files, func(file string, _ int) string { return filepath.ToSlash(filepath.Clean(file)) })
+	for _, file := range lo.Uniq(files) {
+		if file == "." || filepath.IsAbs(file) || file == ".." || strings.HasPrefix(file, "../") {
+			return git.DiffResult{}, fmt.Errorf("file %s must be relative to the repository", file)
+		}
+
+		_, err := os.Stat(filepath.Join(r.Path, file))
+		switch {
+		case err == nil:
+			diff.Updated = append(diff.Updated, file)
+		case errors.Is(err, fs.ErrNotExist):
+			diff.Deleted = append(diff.Deleted, file)
+		default:
+			return git.DiffResult{}, fmt.Errorf("failed to check file %s: %w", file, err)
+		}
+	}
+
+	slices.Sort(diff.Updated)
+	slices.Sort(diff.Deleted)
+	return diff, nil
+}
@@ -0,0 +1,106 @@
+package monogo_test
+
+import (
+	"context"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/brunoluiz/monogo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetector_Affected(t *testing.T) {
+	tests := []struct {
+		name   string
+		files  []string
+		opts   []monogo.WithDetectOpt
+		err    string
+		assert func(t *testing.T, res monogo.DetectRes)
+	}{
+		{
+			name:  "should detect entrypoints using the changed files",
+			files: []string{"./pkg/pkgA/a.go", "go.sum"},
+			assert: func(t *testing.T, res monogo.DetectRes) {
+				require.True(t, res.Changed)
+				require.Equal(t, []string{"app1", "app3"}, entrypointPaths(res.Entrypoints))
+				require.Equal(t, []monogo.ChangeReason{monogo.ChangedFilesReason}, findEntrypoint(res.Entrypoints, "cmd/app1").Reasons)
+				require.Equal(t, []string{"go.sum", "pkg/pkgA/a.go"}, res.Git.Files.Updated.All)
+			},
+		},
+		{
+			name:  "should report missing files as deleted",
+			files: []string{"pkg/pkgA/missing.go"},
+			opts:  []monogo.WithDetectOpt{monogo.WithExplain(true)},
+			assert: func(t *testing.T, res monogo.DetectRes) {
+				require.True(t, res.Changed)
+				require.Equal(t, []string{"pkg/pkgA/missing.go"}, res.Git.Files.Deleted.Go)
+				require.Equal(t, []string{"app1", "app3"}, entrypointPaths(res.Entrypoints))
+				require.Equal(t, []monogo.DetectEvidenceRes{
+					{Reason: monogo.CreatedDeletedFilesReasons, Created: []string{}, Deleted: []string{"pkg/pkgA/missing.go"}},
+				}, findEntrypoint(res.Entrypoints, "cmd/app1").Evidence)
+			},
+		},
+		{
+			name:  "should ignore deleted files outside walked packages",
+			files: []string{"pkg/unknown/missing.go", "pkg/pkgA/missing_test.go"},
+			assert: func(t *testing.T, res monogo.DetectRes) {
+				require.False(t, res.Changed)
+				require.Len(t, res.Git.Files.Deleted.Go, 2)
+			},
+		},
+		{
+			name:  "should mark all entrypoints as changed on global triggers",
+			files: []string{"go.mod"},
+			opts:  []monogo.WithDetectOpt{monogo.WithTriggers([]string{"go.mod"}), monogo.WithShowUnchanged(true)},
+			assert: func(t *testing.T, res monogo.DetectRes) {
+				require.Equal(t, []string{"go.mod"}, res.Triggers)
+				require.Equal(t, []string{"app1", "app2", "app3"}, entrypointPaths(res.Entrypoints))
+				require.Equal(t, []monogo.ChangeReason{monogo.GlobalTriggerReason}, findEntrypoint(res.Entrypoints, "cmd/app2").Reasons)
+			},
+		},
+		{
+			name:  "should apply ignore globs",
+			files: []string{"pkg/shared/shared.go"},
+			opts:  []monogo.WithDetectOpt{monogo.WithIgnore([]string{"pkg/shared/**"})},
+			assert: func(t *testing.T, res monogo.DetectRes) {
+				require.False(t, res.Changed)
+				require.Equal(t, []string{"pkg/shared/shared.go"}, res.Git.Files.Ignored.All)
+			},
+		},
+		{
+			name:  "should reject files outside the repository",
+			files: []string{"../other/main.go"},
+			err:   "file ../other/main.go must be relative to the repository",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir, _, _ := setupTestRepo(t)
+
+			// Git is not used, so the tree could be any directory
+			d := monogo.NewDetector([]string{"cmd/app1", "cmd/app2", "cmd/app3"}, slog.Default(), nil,
+				append(tt.opts, monogo.WithPath(tmpDir))...,
+			)
+			res, err := d.Affected(context.Background(), tt.files)
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			tt.assert(t, res)
+		})
+	}
+}
+
+// entrypointPaths returns the sorted entrypoint base names, as results are not ordered
+func entrypointPaths(entrypoints []monogo.DetectEntrypointRes) []string {
+	names := make([]string, 0, len(entrypoints))
+	for _, entry := range entrypoints {
+		names = append(names, filepath.Base(entry.Path))
+	}
+	slices.Sort(names)
+	return names
+}
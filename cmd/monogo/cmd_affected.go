@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/brunoluiz/monogo"
+)
+
+type AffectedCmd struct {
+	Path          string   `help:"Path to the repository" default:"."`
+	Config        string   `help:"Path to the config file (default: .monogo.yaml within --path, if present)"`
+	Files         []string `help:"Changed files, relative to the repository (e.g., pkg/a.go,pkg/b.go)"`
+	FilesFrom     string   `help:"File listing the changed files, one per line, or '-' to read them from stdin"`
+	Entrypoints   []string `help:"Entrypoints to analyze for changes, or 'auto' to discover main packages"`
+	Discover      []string `help:"Globs filtering discovered entrypoints, prefixed with ! to exclude them (implies --entrypoints auto)"`
+	Libraries     []string `help:"Library package patterns to analyze for changes, reported separately from entrypoints (e.g., ./pkg/sdk/...)"`
+	ShowUnchanged *bool    `help:"Show unchanged entrypoints in the output"`
+	Output        string   `help:"Output format: json or github (default: json)"`
+	Explain       bool     `help:"Attach the evidence of each reason to the entrypoints, such as changed files and import chains"`
+	Triggers      []string `help:"Globs for files marking all entrypoints as changed (e.g., Makefile)"`
+	Ignore        []string `help:"Globs for files excluded from the change set (e.g., **/*.md)"`
+}
+
+func (r *AffectedCmd) Run(c *Context) error {
+	// The settings are shared with the detect command, although refs and policies are not used
+	cfg, _, err := (&DetectCmd{
+		Path:          r.Path,
+		Config:        r.Config,
+		Entrypoints:   r.Entrypoints,
+		Discover:      r.Discover,
+		Libraries:     r.Libraries,
+		ShowUnchanged: r.ShowUnchanged,
+		Output:        r.Output,
+		Triggers:      r.Triggers,
+		Ignore:        r.Ignore,
+	}).config()
+	if err != nil {
+		return err
+	}
+	if len(cfg.Entrypoints) == 0 && len(cfg.Libraries) == 0 && cfg.Discover == nil {
+		return fmt.Errorf("entrypoints are required: set --entrypoints, --libraries or their config keys")
+	}
+
+	files, err := r.files()
+	if err != nil {
+		return err
+	}
+
+	opts := append(cfg.DetectorOpts(), monogo.WithPath(r.Path), monogo.WithExplain(r.Explain))
+	detector := monogo.NewDetector(cfg.EntrypointPaths(), c.Logger, nil, opts...)
+	out, err := detector.Affected(c.Context, files)
+	if err != nil {
+		return fmt.Errorf("failed to run affected command: %w", err)
+	}
+
+	switch cfg.Output {
+	case "json":
+		if err := json.NewEncoder(os.Stdout).Encode(out); err != nil {
+			return fmt.Errorf("failed to encode output: %w", err)
+		}
+	case "github":
+		if err := outputGitHub(out); err != nil {
+			return fmt.Errorf("failed to output github format: %w", err)
+		}
+	default:
+		return fmt.Errorf("unknown output format: %s", cfg.Output)
+	}
+
+	return nil
+}
+
+// files returns the files set as flags plus the ones listed in --files-from
+func (r *AffectedCmd) files() ([]string, error) {
+	files := append([]string{}, r.Files...)
+	if r.FilesFrom == "" {
+		return files, nil
+	}
+
+	var in io.Reader = os.Stdin
+	if r.FilesFrom != "-" {
+		f, err := os.Open(r.FilesFrom)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open files list: %w", err)
+		}
+		defer f.Close()
+		in = f
+	}
+
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		if file := strings.TrimSpace(scanner.Text()); file != "" {
+			files = append(files, file)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read files list: %w", err)
+	}
+	return files, nil
+}
@@ -2,43 +2,91 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/brunoluiz/monogo"
+	"github.com/brunoluiz/monogo/diskcache"
 	"github.com/brunoluiz/monogo/git"
+	"github.com/brunoluiz/monogo/glob"
+	"github.com/brunoluiz/monogo/mod"
+	"github.com/brunoluiz/monogo/patch"
 	"github.com/samber/lo"
 )
 
 type DetectCmd struct {
 	Path          string   `help:"Path to detect changes" default:"."`
-	BaseRef       string   `default:"refs/heads/main" help:"Base reference, usually main (e.g., refs/heads/main)"`
-	CompareRef    string   `required:"" help:"Compare reference, usually your feature branch (e.g., refs/heads/my-branch)"`
-	Entrypoints   []string `required:"" help:"Entrypoints to analyze for changes"`
-	ShowUnchanged bool     `help:"Show unchanged entrypoints in the output" default:"false"`
-	Output        string   `help:"Output format: json or github" default:"json" enum:"json,github"`
+	Config        string   `help:"Path to the config file (default: .monogo.yaml within --path, if present)"`
+	BaseRef       string   `help:"Base reference, usually main (default: refs/heads/main)"`
+	CompareRef    string   `help:"Compare reference, usually your feature branch (e.g., refs/heads/my-branch)"`
+	Patch         string   `help:"Unified diff already applied to the tree, used as the change set instead of git refs, or '-' to read it from stdin"`
+	BaseSnapshot  string   `help:"Snapshot of the base ref created by 'monogo snapshot', used instead of checking out the base ref"`
+	Entrypoints   []string `help:"Entrypoints to analyze for changes, or 'auto' to discover main packages"`
+	Discover      []string `help:"Globs filtering discovered entrypoints, prefixed with ! to exclude them (implies --entrypoints auto)"`
+	Libraries     []string `help:"Library package patterns to analyze for changes, reported separately from entrypoints (e.g., ./pkg/sdk/...)"`
+	ShowUnchanged *bool    `help:"Show unchanged entrypoints in the output"`
+	Output        string   `help:"Output format: json or github (default: json)"`
+	Explain       bool     `help:"Attach the evidence of each reason to the entrypoints, such as changed files, import chains and modules"`
+	CacheDir      string   `help:"Directory caching the packages loaded from each ref tree, shared between runs (e.g., ~/.cache/monogo)"`
+
+	GoVersionPolicy string   `help:"Which go directive changes mark all entrypoints as changed: all, none, minor-only or patch-ignored (default: all)"`
+	ToolchainPolicy string   `help:"Which toolchain changes mark all entrypoints as changed: all, none, minor-only or patch-ignored (default: all)"`
+	GodebugScope    []string `help:"Entrypoint globs affected by go.mod godebug changes (default: all entrypoints)"`
+	Triggers        []string `help:"Globs for files marking all entrypoints as changed (e.g., Makefile)"`
+	Ignore          []string `help:"Globs for files excluded from the git diff (e.g., **/*.md)"`
 }
 
 func (r *DetectCmd) Run(c *Context) error {
-	g, err := git.New(git.WithPath(r.Path))
+	cfg, cfgPath, err := r.config()
 	if err != nil {
+		return err
+	}
+	if cfg.Refs.Compare == "" && r.Patch == "" {
+		return fmt.Errorf("compare ref is required: set --compare-ref or refs.compare in the config")
+	}
+	if len(cfg.Entrypoints) == 0 && len(cfg.Libraries) == 0 && cfg.Discover == nil {
+		return fmt.Errorf("entrypoints are required: set --entrypoints, --libraries or their config keys")
+	}
+
+	opts := append(cfg.DetectorOpts(), monogo.WithPath(r.Path), monogo.WithExplain(r.Explain))
+	if cfgPath != "" {
+		opts = append(opts, monogo.WithConfigFile(cfgPath))
+	}
+	if opts, err = withCache(opts, r.CacheDir); err != nil {
+		return err
+	}
+	if r.BaseSnapshot != "" {
+		snapshot, err := readSnapshot(r.BaseSnapshot)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, monogo.WithBaseSnapshot(&snapshot))
+	}
+
+	// Patches replace git as the source of both trees, so refs are not used
+	var g *git.Git
+	if r.Patch != "" {
+		source, err := r.patchSource()
+		if err != nil {
+			return err
+		}
+		opts = append(opts, monogo.WithSource(source), monogo.WithBaseRef(patch.BaseRef), monogo.WithCompareRef(patch.CompareRef))
+	} else if g, err = git.New(git.WithPath(r.Path)); err != nil {
 		return fmt.Errorf("failed to open git repository: %w", err)
 	}
 
-	detector := monogo.NewDetector(r.Entrypoints, c.Logger, g,
-		monogo.WithBaseRef(r.BaseRef),
-		monogo.WithPath(r.Path),
-		monogo.WithCompareRef(r.CompareRef),
-		monogo.WithShowUnchanged(r.ShowUnchanged),
-	)
+	detector := monogo.NewDetector(cfg.EntrypointPaths(), c.Logger, g, opts...)
 	out, err := detector.Run(c.Context)
 	if err != nil {
 		return fmt.Errorf("failed to run detect command: %w", err)
 	}
 
-	switch r.Output {
+	switch cfg.Output {
 	case "json":
 		if err := json.NewEncoder(os.Stdout).Encode(out); err != nil {
 			return fmt.Errorf("failed to encode output: %w", err)
@@ -48,12 +96,135 @@ func (r *DetectCmd) Run(c *Context) error {
 			return fmt.Errorf("failed to output github format: %w", err)
 		}
 	default:
-		return fmt.Errorf("unknown output format: %s", r.Output)
+		return fmt.Errorf("unknown output format: %s", cfg.Output)
 	}
 
 	return nil
 }
 
+// patchSource reads the patch from the file set in --patch, or from stdin
+func (r *DetectCmd) patchSource() (*patch.Source, error) {
+	var in io.Reader = os.Stdin
+	if r.Patch != "-" {
+		f, err := os.Open(r.Patch)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open patch: %w", err)
+		}
+		defer f.Close()
+		in = f
+	}
+
+	source, err := patch.NewSource(r.Path, in)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse patch: %w", err)
+	}
+	return source, nil
+}
+
+// readSnapshot reads the snapshot file
+func readSnapshot(path string) (monogo.Snapshot, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return monogo.Snapshot{}, fmt.Errorf("failed to open snapshot: %w", err)
+	}
+	defer f.Close()
+
+	return monogo.ReadSnapshot(f)
+}
+
+// withCache adds the cache option, if a cache directory is set
+func withCache(opts []monogo.WithDetectOpt, dir string) ([]monogo.WithDetectOpt, error) {
+	if dir == "" {
+		return opts, nil
+	}
+
+	c, err := diskcache.New(dir)
+	if err != nil {
+		return nil, err
+	}
+	return append(opts, monogo.WithCache(c)), nil
+}
+
+// config reads the config file, if any, and overrides it with the flags set. The config file path
+// is only returned if it exists.
+func (r *DetectCmd) config() (monogo.Config, string, error) {
+	cfg := monogo.DefaultConfig()
+	path := r.Config
+	if path == "" {
+		path = filepath.Join(r.Path, monogo.DefaultConfigFile)
+	}
+
+	_, err := os.Stat(path)
+	switch {
+	case err == nil:
+		if cfg, err = monogo.ReadConfig(path); err != nil {
+			return monogo.Config{}, "", err
+		}
+	case !errors.Is(err, fs.ErrNotExist) || r.Config != "":
+		return monogo.Config{}, "", fmt.Errorf("failed to read config: %w", err)
+	default:
+		path = ""
+	}
+
+	if r.BaseRef != "" {
+		cfg.Refs.Base = r.BaseRef
+	}
+	if r.CompareRef != "" {
+		cfg.Refs.Compare = r.CompareRef
+	}
+	if isAuto(r.Entrypoints) || len(r.Discover) > 0 {
+		if cfg.Discover == nil {
+			cfg.Discover = &monogo.ConfigDiscover{}
+		}
+		if len(r.Discover) > 0 {
+			cfg.Discover.Patterns = r.Discover
+		}
+	} else if len(r.Entrypoints) > 0 {
+		// Explicit entrypoints replace the discovery from the config
+		cfg.Discover = nil
+		cfg.Entrypoints = withPaths(cfg.Entrypoints, r.Entrypoints)
+	}
+	if len(r.Libraries) > 0 {
+		cfg.Libraries = withPaths(cfg.Libraries, r.Libraries)
+	}
+	if r.ShowUnchanged != nil {
+		cfg.ShowUnchanged = *r.ShowUnchanged
+	}
+	if r.Output != "" {
+		cfg.Output = r.Output
+	}
+	if r.GoVersionPolicy != "" {
+		cfg.Policies.GoVersion = mod.Policy(r.GoVersionPolicy)
+	}
+	if r.ToolchainPolicy != "" {
+		cfg.Policies.Toolchain = mod.Policy(r.ToolchainPolicy)
+	}
+	if len(r.GodebugScope) > 0 {
+		cfg.Godebug.Scope = r.GodebugScope
+	}
+	if len(r.Triggers) > 0 {
+		cfg.Triggers = r.Triggers
+	}
+	if len(r.Ignore) > 0 {
+		cfg.Ignore = r.Ignore
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return monogo.Config{}, "", fmt.Errorf("invalid settings: %w", err)
+	}
+	return cfg, path, nil
+}
+
+// withPaths replaces the configured entrypoints by the paths set as flags. Entrypoints from the config keep
+// their settings when also set as flags.
+func withPaths(entrypoints []monogo.ConfigEntrypoint, paths []string) []monogo.ConfigEntrypoint {
+	return lo.Map(paths, func(path string, _ int) monogo.ConfigEntrypoint {
+		entry, _ := lo.Find(entrypoints, func(e monogo.ConfigEntrypoint) bool { return glob.Clean(e.Path) == glob.Clean(path) })
+		entry.Path = path
+		return entry
+	})
+}
+
 func outputGitHub(out monogo.DetectRes) error {
 	jsonBytes, err := json.Marshal(out)
 	if err != nil {
@@ -65,6 +236,16 @@ func outputGitHub(out monogo.DetectRes) error {
 		return fmt.Errorf("failed to marshal entrypoints: %w", err)
 	}
 
+	librariesBytes, err := json.Marshal(out.Libraries)
+	if err != nil {
+		return fmt.Errorf("failed to marshal libraries: %w", err)
+	}
+
+	wavesBytes, err := json.Marshal(out.Waves)
+	if err != nil {
+		return fmt.Errorf("failed to marshal waves: %w", err)
+	}
+
 	impactedFolders := lo.Reduce(out.Git.Files.Impacted.Go,
 		func(folders []string, file string, index int) []string {
 			folder := filepath.Dir(file)
@@ -78,9 +259,14 @@ func outputGitHub(out monogo.DetectRes) error {
 
 	fmt.Printf("json=%s\n", string(jsonBytes))
 	fmt.Printf("entrypoints=%s\n", string(entrypointsBytes))
+	fmt.Printf("libraries=%s\n", string(librariesBytes))
+	fmt.Printf("waves=%s\n", string(wavesBytes))
 	fmt.Printf("impacted_go_files=%s\n", strings.Join(out.Git.Files.Impacted.Go, " "))
 	fmt.Printf("impacted_go_folders=%s\n", strings.Join(impactedFolders, " "))
 	fmt.Printf("changed=%t\n", out.Changed)
+	fmt.Printf("libraries_changed=%t\n", out.LibrariesChanged)
+	fmt.Printf("triggers=%s\n", strings.Join(out.Triggers, " "))
+	fmt.Printf("tools_changed=%t\n", lo.Contains(out.Reasons, monogo.ToolsChangedReason))
 
 	return nil
 }
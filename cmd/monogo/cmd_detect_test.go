@@ -0,0 +1,58 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/brunoluiz/monogo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectCmd_config(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		cmd    DetectCmd
+		assert func(t *testing.T, cfg monogo.Config)
+	}{
+		{
+			name:   "should replace discovery by explicit entrypoints",
+			config: "version: 1\ndiscover:\n  patterns: [\"cmd/*\"]\n",
+			cmd:    DetectCmd{Entrypoints: []string{"cmd/app1", "cmd/app2"}},
+			assert: func(t *testing.T, cfg monogo.Config) {
+				require.Nil(t, cfg.Discover)
+				require.Equal(t, []string{"cmd/app1", "cmd/app2"}, cfg.EntrypointPaths())
+			},
+		},
+		{
+			name:   "should keep config settings of entrypoints set as flags",
+			config: "version: 1\nentrypoints:\n  - path: cmd/app1\n    name: app1\n    inputs: [Dockerfile]\n  - path: cmd/app2\n",
+			cmd:    DetectCmd{Entrypoints: []string{"./cmd/app1"}},
+			assert: func(t *testing.T, cfg monogo.Config) {
+				require.Equal(t, []monogo.ConfigEntrypoint{{Path: "./cmd/app1", Name: "app1", Inputs: []string{"Dockerfile"}}}, cfg.Entrypoints)
+			},
+		},
+		{
+			name:   "should keep discovery from the config without entrypoint flags",
+			config: "version: 1\ndiscover:\n  patterns: [\"cmd/*\"]\n",
+			assert: func(t *testing.T, cfg monogo.Config) {
+				require.Equal(t, []string{"cmd/*"}, cfg.Discover.Patterns)
+				require.Empty(t, cfg.EntrypointPaths())
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, monogo.DefaultConfigFile), []byte(tt.config), 0o600))
+
+			tt.cmd.Path = dir
+			cfg, path, err := tt.cmd.config()
+			require.NoError(t, err)
+			require.Equal(t, filepath.Join(dir, monogo.DefaultConfigFile), path)
+			tt.assert(t, cfg)
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/brunoluiz/monogo"
+	"github.com/brunoluiz/monogo/dirs"
+)
+
+type DiffDirsCmd struct {
+	Base          string   `help:"Directory with the base snapshot of the repository (e.g., ./release-1.4)" required:""`
+	Compare       string   `help:"Directory with the compare snapshot of the repository (e.g., ./release-1.5)" required:""`
+	Config        string   `help:"Path to the config file (default: .monogo.yaml within --compare, if present)"`
+	Entrypoints   []string `help:"Entrypoints to analyze for changes, or 'auto' to discover main packages"`
+	Discover      []string `help:"Globs filtering discovered entrypoints, prefixed with ! to exclude them (implies --entrypoints auto)"`
+	Libraries     []string `help:"Library package patterns to analyze for changes, reported separately from entrypoints (e.g., ./pkg/sdk/...)"`
+	ShowUnchanged *bool    `help:"Show unchanged entrypoints in the output"`
+	Output        string   `help:"Output format: json or github (default: json)"`
+	Explain       bool     `help:"Attach the evidence of each reason to the entrypoints, such as changed files, import chains and modules"`
+	CacheDir      string   `help:"Directory caching the packages loaded from each snapshot, shared between runs (e.g., ~/.cache/monogo)"`
+
+	GoVersionPolicy string   `help:"Which go directive changes mark all entrypoints as changed: all, none, minor-only or patch-ignored (default: all)"`
+	ToolchainPolicy string   `help:"Which toolchain changes mark all entrypoints as changed: all, none, minor-only or patch-ignored (default: all)"`
+	GodebugScope    []string `help:"Entrypoint globs affected by go.mod godebug changes (default: all entrypoints)"`
+	Triggers        []string `help:"Globs for files marking all entrypoints as changed (e.g., Makefile)"`
+	Ignore          []string `help:"Globs for files excluded from the diff (e.g., **/*.md)"`
+}
+
+func (r *DiffDirsCmd) Run(c *Context) error {
+	// The settings are shared with the detect command, where the compare directory is the repository path
+	cfg, cfgPath, err := (&DetectCmd{
+		Path:            r.Compare,
+		Config:          r.Config,
+		Entrypoints:     r.Entrypoints,
+		Discover:        r.Discover,
+		Libraries:       r.Libraries,
+		ShowUnchanged:   r.ShowUnchanged,
+		Output:          r.Output,
+		GoVersionPolicy: r.GoVersionPolicy,
+		ToolchainPolicy: r.ToolchainPolicy,
+		GodebugScope:    r.GodebugScope,
+		Triggers:        r.Triggers,
+		Ignore:          r.Ignore,
+	}).config()
+	if err != nil {
+		return err
+	}
+	if len(cfg.Entrypoints) == 0 && len(cfg.Libraries) == 0 && cfg.Discover == nil {
+		return fmt.Errorf("entrypoints are required: set --entrypoints, --libraries or their config keys")
+	}
+
+	source, err := dirs.NewSource(r.Base, r.Compare)
+	if err != nil {
+		return fmt.Errorf("failed to read directories: %w", err)
+	}
+
+	opts := append(cfg.DetectorOpts(),
+		monogo.WithPath(r.Compare),
+		monogo.WithExplain(r.Explain),
+		monogo.WithSource(source),
+		monogo.WithBaseRef(dirs.BaseRef),
+		monogo.WithCompareRef(dirs.CompareRef),
+	)
+	if cfgPath != "" {
+		opts = append(opts, monogo.WithConfigFile(cfgPath))
+	}
+	if opts, err = withCache(opts, r.CacheDir); err != nil {
+		return err
+	}
+	detector := monogo.NewDetector(cfg.EntrypointPaths(), c.Logger, nil, opts...)
+	out, err := detector.Run(c.Context)
+	if err != nil {
+		return fmt.Errorf("failed to run diff-dirs command: %w", err)
+	}
+
+	switch cfg.Output {
+	case "json":
+		if err := json.NewEncoder(os.Stdout).Encode(out); err != nil {
+			return fmt.Errorf("failed to encode output: %w", err)
+		}
+	case "github":
+		if err := outputGitHub(out); err != nil {
+			return fmt.Errorf("failed to output github format: %w", err)
+		}
+	default:
+		return fmt.Errorf("unknown output format: %s", cfg.Output)
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/brunoluiz/monogo"
+	"github.com/brunoluiz/monogo/git"
+	"github.com/brunoluiz/monogo/graph"
+)
+
+type GraphCmd struct {
+	Path        string   `help:"Path to the repository" default:"."`
+	Entrypoints []string `required:"" help:"Entrypoints to analyze, or 'auto' to discover main packages"`
+	Libraries   []string `help:"Library package patterns also included in the graph (e.g., ./pkg/sdk/...)"`
+	Format      string   `help:"Output format: dot, mermaid or json" default:"dot" enum:"dot,mermaid,json"`
+	Collapse    int      `help:"Collapse packages by directory, keeping up to N path components (e.g., 2 merges pkg/a/b into pkg/a)"`
+	BaseRef     string   `help:"Base reference, used to highlight changed packages" default:"refs/heads/main"`
+	CompareRef  string   `help:"Compare reference, used to build the graph and highlight changed packages (default: the current tree, without highlights)"`
+	Ignore      []string `help:"Globs for files excluded from the git diff (e.g., **/*.md)"`
+}
+
+func (r *GraphCmd) Run(c *Context) error {
+	entrypoints, opts := entrypointOpts(r.Entrypoints)
+	opts = append(opts,
+		monogo.WithPath(r.Path),
+		monogo.WithLibraries(r.Libraries),
+		monogo.WithBaseRef(r.BaseRef),
+		monogo.WithCompareRef(r.CompareRef),
+		monogo.WithIgnore(r.Ignore),
+	)
+
+	// Git is only needed to highlight the changes between refs
+	var g *git.Git
+	if r.CompareRef != "" {
+		var err error
+		if g, err = git.New(git.WithPath(r.Path)); err != nil {
+			return fmt.Errorf("failed to open git repository: %w", err)
+		}
+	}
+
+	detector := monogo.NewDetector(entrypoints, c.Logger, g, opts...)
+	out, err := detector.Graph(c.Context)
+	if err != nil {
+		return fmt.Errorf("failed to run graph command: %w", err)
+	}
+	out = out.Collapse(r.Collapse)
+
+	switch r.Format {
+	case "dot":
+		err = graph.WriteDOT(os.Stdout, out)
+	case "mermaid":
+		err = graph.WriteMermaid(os.Stdout, out)
+	case "json":
+		err = json.NewEncoder(os.Stdout).Encode(out)
+	default:
+		return fmt.Errorf("unknown output format: %s", r.Format)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to output graph: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/brunoluiz/monogo"
+	"github.com/brunoluiz/monogo/git"
+)
+
+type ModCmd struct {
+	Unused ModUnusedCmd `cmd:"" help:"Report go.mod requirements unused by any entrypoint"`
+}
+
+type ModUnusedCmd struct {
+	Path        string   `help:"Path to the repository" default:"."`
+	Ref         string   `default:"HEAD" help:"Reference to analyze (e.g., refs/heads/main)"`
+	Entrypoints []string `required:"" help:"Entrypoints to analyze, or 'auto' to discover main packages"`
+	Tests       bool     `help:"Include test dependencies, from entrypoints and all module packages"`
+	Tools       bool     `help:"Include dependencies from go.mod tool directives"`
+	Output      string   `help:"Output format: json or text" default:"json" enum:"json,text"`
+}
+
+func (r *ModUnusedCmd) Run(c *Context) error {
+	g, err := git.New(git.WithPath(r.Path))
+	if err != nil {
+		return fmt.Errorf("failed to open git repository: %w", err)
+	}
+
+	entrypoints, opts := entrypointOpts(r.Entrypoints)
+	detector := monogo.NewDetector(entrypoints, c.Logger, g, append(opts, monogo.WithPath(r.Path))...)
+	out, err := detector.ModUnused(c.Context, r.Ref, monogo.ModUnusedOpts{Tests: r.Tests, Tools: r.Tools})
+	if err != nil {
+		return fmt.Errorf("failed to run mod unused command: %w", err)
+	}
+
+	switch r.Output {
+	case "json":
+		if err := json.NewEncoder(os.Stdout).Encode(out); err != nil {
+			return fmt.Errorf("failed to encode output: %w", err)
+		}
+	case "text":
+		fmt.Println("unused:")
+		for _, req := range out.Unused {
+			fmt.Printf("  %s %s\n", req.Path, req.Version)
+		}
+		fmt.Println("single user:")
+		for _, req := range out.SingleUser {
+			fmt.Printf("  %s %s (%s)\n", req.Path, req.Version, strings.Join(req.Users, ", "))
+		}
+	default:
+		return fmt.Errorf("unknown output format: %s", r.Output)
+	}
+
+	return nil
+}
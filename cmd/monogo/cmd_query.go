@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/brunoluiz/monogo/graph"
+	"github.com/brunoluiz/monogo/walker"
+)
+
+type QueryCmd struct {
+	Expr        string   `arg:"" help:"Query expression (e.g., 'rdeps(./pkg/shared) intersect entrypoints()')"`
+	Path        string   `help:"Path to the repository" default:"."`
+	Entrypoints []string `help:"Packages matched by entrypoints() (default: all main packages)"`
+	External    bool     `help:"Include packages outside the module, such as dependencies and the standard library"`
+	Output      string   `help:"Output format: json or text" default:"text" enum:"json,text"`
+}
+
+func (r *QueryCmd) Run(c *Context) error {
+	w, err := walker.New(r.Path, c.Logger.WithGroup("walker"))
+	if err != nil {
+		return err
+	}
+
+	g, err := graph.Load(c.Context, w, r.External)
+	if err != nil {
+		return fmt.Errorf("failed to load graph: %w", err)
+	}
+	if len(r.Entrypoints) > 0 && !isAuto(r.Entrypoints) {
+		ids := []string{}
+		for _, entry := range r.Entrypoints {
+			id, ok := g.Lookup(entry)
+			if !ok {
+				return fmt.Errorf("entrypoint %s not found", entry)
+			}
+			ids = append(ids, id)
+		}
+		for i := range g.Nodes {
+			g.Nodes[i].Entrypoint = slices.Contains(ids, g.Nodes[i].ID)
+		}
+	}
+
+	out, err := g.Query(r.Expr)
+	if err != nil {
+		return fmt.Errorf("invalid query: %w", err)
+	}
+
+	switch r.Output {
+	case "json":
+		if err := json.NewEncoder(os.Stdout).Encode(out); err != nil {
+			return fmt.Errorf("failed to encode output: %w", err)
+		}
+	case "text":
+		for _, id := range out {
+			fmt.Println(id)
+		}
+	default:
+		return fmt.Errorf("unknown output format: %s", r.Output)
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/brunoluiz/monogo"
+	"github.com/brunoluiz/monogo/git"
+	"github.com/brunoluiz/monogo/glob"
+	"github.com/brunoluiz/monogo/sbom"
+)
+
+type SBOMCmd struct {
+	Path        string   `help:"Path to the repository" default:"."`
+	Ref         string   `default:"HEAD" help:"Reference to generate the SBOM for (e.g., refs/heads/main)"`
+	Entrypoints []string `required:"" help:"Entrypoints to generate SBOMs for, or 'auto' to discover main packages"`
+	Format      string   `help:"SBOM format: cyclonedx or spdx" default:"cyclonedx" enum:"cyclonedx,spdx"`
+	OutputDir   string   `help:"Directory to write one document per entrypoint to. Documents are written to stdout if not set"`
+}
+
+func (r *SBOMCmd) Run(c *Context) error {
+	g, err := git.New(git.WithPath(r.Path))
+	if err != nil {
+		return fmt.Errorf("failed to open git repository: %w", err)
+	}
+
+	entrypoints, opts := entrypointOpts(r.Entrypoints)
+	detector := monogo.NewDetector(entrypoints, c.Logger, g, append(opts, monogo.WithPath(r.Path))...)
+	docs, err := detector.SBOM(c.Context, r.Ref)
+	if err != nil {
+		return fmt.Errorf("failed to run sbom command: %w", err)
+	}
+
+	format := sbom.Format(r.Format)
+	for _, doc := range docs {
+		data, err := sbom.Encode(doc, format)
+		if err != nil {
+			return err
+		}
+
+		if r.OutputDir == "" {
+			fmt.Println(string(data))
+			continue
+		}
+
+		// cmd/app -> cmd_app.cdx.json
+		name := strings.ReplaceAll(glob.Clean(doc.Entrypoint), "/", "_") + sbom.Extension(format)
+		if err := os.MkdirAll(r.OutputDir, 0o755); err != nil {
+			return fmt.Errorf("failed to create output dir: %w", err)
+		}
+		if err := os.WriteFile(filepath.Join(r.OutputDir, name), data, 0o600); err != nil {
+			return fmt.Errorf("failed to write sbom: %w", err)
+		}
+	}
+
+	return nil
+}
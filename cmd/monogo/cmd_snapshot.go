@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/brunoluiz/monogo"
+	"github.com/brunoluiz/monogo/git"
+)
+
+type SnapshotCmd struct {
+	Path        string   `help:"Path to the repository" default:"."`
+	Config      string   `help:"Path to the config file (default: .monogo.yaml within --path, if present)"`
+	Ref         string   `help:"Reference to analyze, usually main (default: refs/heads/main or refs.base in the config)"`
+	Output      string   `short:"o" help:"File to write the snapshot to, or '-' for stdout" default:"-"`
+	Entrypoints []string `help:"Entrypoints to analyze, or 'auto' to discover main packages"`
+	Discover    []string `help:"Globs filtering discovered entrypoints, prefixed with ! to exclude them (implies --entrypoints auto)"`
+	Libraries   []string `help:"Library package patterns to analyze (e.g., ./pkg/sdk/...)"`
+	Graph       bool     `help:"Include the import graph of all module packages"`
+	CacheDir    string   `help:"Directory caching the packages loaded from each ref tree, shared between runs (e.g., ~/.cache/monogo)"`
+}
+
+func (r *SnapshotCmd) Run(c *Context) error {
+	// The settings are shared with the detect command, as they must match when the snapshot is consumed
+	cfg, cfgPath, err := (&DetectCmd{
+		Path:        r.Path,
+		Config:      r.Config,
+		BaseRef:     r.Ref,
+		Entrypoints: r.Entrypoints,
+		Discover:    r.Discover,
+		Libraries:   r.Libraries,
+	}).config()
+	if err != nil {
+		return err
+	}
+	if len(cfg.Entrypoints) == 0 && len(cfg.Libraries) == 0 && cfg.Discover == nil {
+		return fmt.Errorf("entrypoints are required: set --entrypoints, --libraries or their config keys")
+	}
+
+	g, err := git.New(git.WithPath(r.Path))
+	if err != nil {
+		return fmt.Errorf("failed to open git repository: %w", err)
+	}
+
+	opts := append(cfg.DetectorOpts(), monogo.WithPath(r.Path))
+	if cfgPath != "" {
+		opts = append(opts, monogo.WithConfigFile(cfgPath))
+	}
+	if opts, err = withCache(opts, r.CacheDir); err != nil {
+		return err
+	}
+
+	detector := monogo.NewDetector(cfg.EntrypointPaths(), c.Logger, g, opts...)
+	out, err := detector.Snapshot(c.Context, cfg.Refs.Base, r.Graph)
+	if err != nil {
+		return fmt.Errorf("failed to run snapshot command: %w", err)
+	}
+
+	var w io.Writer = os.Stdout
+	if r.Output != "-" {
+		f, err := os.Create(r.Output)
+		if err != nil {
+			return fmt.Errorf("failed to create snapshot file: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if err := json.NewEncoder(w).Encode(out); err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	return nil
+}
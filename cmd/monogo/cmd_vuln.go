@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/brunoluiz/monogo"
+	"github.com/brunoluiz/monogo/git"
+	"github.com/brunoluiz/monogo/vuln"
+	"github.com/samber/lo"
+)
+
+type VulnCmd struct {
+	Path        string   `help:"Path to the repository" default:"."`
+	DB          string   `name:"db" required:"" help:"Path to a local OSV vulnerability database directory (eg: an extracted Go vulndb dump)"`
+	BaseRef     string   `default:"refs/heads/main" help:"Base reference, usually main (e.g., refs/heads/main)"`
+	CompareRef  string   `required:"" help:"Compare reference, usually your feature branch (e.g., refs/heads/my-branch)"`
+	Entrypoints []string `required:"" help:"Entrypoints to analyze for vulnerabilities, or 'auto' to discover main packages"`
+	Output      string   `help:"Output format: json or github" default:"json" enum:"json,github"`
+}
+
+func (r *VulnCmd) Run(c *Context) error {
+	db, err := vuln.Load(r.DB)
+	if err != nil {
+		return err
+	}
+	c.Logger.Debug("vulnerability database loaded", "entries", db.Len())
+
+	g, err := git.New(git.WithPath(r.Path))
+	if err != nil {
+		return fmt.Errorf("failed to open git repository: %w", err)
+	}
+
+	entrypoints, opts := entrypointOpts(r.Entrypoints)
+	detector := monogo.NewDetector(entrypoints, c.Logger, g, append(opts,
+		monogo.WithBaseRef(r.BaseRef),
+		monogo.WithPath(r.Path),
+		monogo.WithCompareRef(r.CompareRef),
+	)...)
+	out, err := detector.Vuln(c.Context, db)
+	if err != nil {
+		return fmt.Errorf("failed to run vuln command: %w", err)
+	}
+
+	switch r.Output {
+	case "json":
+		if err := json.NewEncoder(os.Stdout).Encode(out); err != nil {
+			return fmt.Errorf("failed to encode output: %w", err)
+		}
+	case "github":
+		if err := outputVulnGitHub(out); err != nil {
+			return fmt.Errorf("failed to output github format: %w", err)
+		}
+	default:
+		return fmt.Errorf("unknown output format: %s", r.Output)
+	}
+
+	return nil
+}
+
+func outputVulnGitHub(out monogo.VulnRes) error {
+	jsonBytes, err := json.Marshal(out)
+	if err != nil {
+		return fmt.Errorf("failed to marshal json: %w", err)
+	}
+
+	vulnerable := lo.Filter(out.Entrypoints, func(item monogo.VulnEntrypointRes, _ int) bool {
+		return item.Vulnerable
+	})
+	vulnerableBytes, err := json.Marshal(vulnerable)
+	if err != nil {
+		return fmt.Errorf("failed to marshal entrypoints: %w", err)
+	}
+
+	introduced := lo.SomeBy(out.Entrypoints, func(item monogo.VulnEntrypointRes) bool {
+		return len(item.Introduced) > 0
+	})
+
+	fmt.Printf("json=%s\n", string(jsonBytes))
+	fmt.Printf("entrypoints=%s\n", string(vulnerableBytes))
+	fmt.Printf("vulnerable=%t\n", out.Vulnerable)
+	fmt.Printf("introduced=%t\n", introduced)
+
+	return nil
+}
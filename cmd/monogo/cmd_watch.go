@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/brunoluiz/monogo"
+	"github.com/brunoluiz/monogo/git"
+	"github.com/samber/lo"
+)
+
+type WatchCmd struct {
+	Path        string        `help:"Path to the repository" default:"."`
+	Config      string        `help:"Path to the config file (default: .monogo.yaml within --path, if present)"`
+	BaseRef     string        `help:"Base reference for the initial report of worktree changes (default: refs/heads/main)"`
+	Entrypoints []string      `help:"Entrypoints to analyze for changes, or 'auto' to discover main packages"`
+	Discover    []string      `help:"Globs filtering discovered entrypoints, prefixed with ! to exclude them (implies --entrypoints auto)"`
+	Libraries   []string      `help:"Library package patterns to analyze for changes, reported separately from entrypoints (e.g., ./pkg/sdk/...)"`
+	Interval    time.Duration `help:"Interval between polls of the working tree" default:"1s"`
+	Exec        string        `help:"Command run with 'sh -c' for each affected entrypoint, with MONOGO_ENTRYPOINT and MONOGO_ENTRYPOINT_NAME set (e.g., 'make restart')"`
+	Output      string        `help:"Output format: json (one event per line) or text" default:"json"`
+	Triggers    []string      `help:"Globs for files marking all entrypoints as changed (e.g., Makefile)"`
+	Ignore      []string      `help:"Globs for files excluded from the change set (e.g., **/*.md)"`
+}
+
+func (r *WatchCmd) Run(c *Context) error {
+	// The settings are shared with the detect command, although the compare ref is the working tree
+	cfg, cfgPath, err := (&DetectCmd{
+		Path:        r.Path,
+		Config:      r.Config,
+		BaseRef:     r.BaseRef,
+		Entrypoints: r.Entrypoints,
+		Discover:    r.Discover,
+		Libraries:   r.Libraries,
+		Triggers:    r.Triggers,
+		Ignore:      r.Ignore,
+	}).config()
+	if err != nil {
+		return err
+	}
+	if len(cfg.Entrypoints) == 0 && len(cfg.Libraries) == 0 && cfg.Discover == nil {
+		return fmt.Errorf("entrypoints are required: set --entrypoints, --libraries or their config keys")
+	}
+	if r.Output != "json" && r.Output != "text" {
+		return fmt.Errorf("unknown output format: %s", r.Output)
+	}
+
+	opts := append(cfg.DetectorOpts(), monogo.WithPath(r.Path))
+	if cfgPath != "" {
+		opts = append(opts, monogo.WithConfigFile(cfgPath))
+	}
+
+	// Outside git repositories only the changes made while watching are reported
+	g, err := git.New(git.WithPath(r.Path))
+	if err != nil {
+		c.Logger.Warn("git repository not found, skipping worktree changes", "error", err)
+		g = nil
+	}
+
+	detector := monogo.NewDetector(cfg.EntrypointPaths(), c.Logger, g, opts...)
+	return detector.Watch(c.Context, r.Interval, func(event monogo.WatchEvent) error {
+		if err := r.output(event); err != nil {
+			return err
+		}
+		if r.Exec != "" {
+			r.exec(c, event)
+		}
+		return nil
+	})
+}
+
+// output writes the event to stdout
+func (r *WatchCmd) output(event monogo.WatchEvent) error {
+	if r.Output == "json" {
+		if err := json.NewEncoder(os.Stdout).Encode(event); err != nil {
+			return fmt.Errorf("failed to encode output: %w", err)
+		}
+		return nil
+	}
+
+	for _, entry := range event.Entrypoints {
+		reasons := lo.Map(entry.Reasons, func(reason monogo.ChangeReason, _ int) string { return string(reason) })
+		fmt.Printf("%s %s (%s)\n", event.Time.Format(time.TimeOnly), entry.Path, strings.Join(reasons, ", "))
+	}
+	return nil
+}
+
+// exec runs the command for each affected entrypoint. Failures are logged, so watching continues.
+func (r *WatchCmd) exec(c *Context, event monogo.WatchEvent) {
+	for _, entry := range event.Entrypoints {
+		cmd := exec.CommandContext(c.Context, "sh", "-c", r.Exec) // nolint:gosec
+		cmd.Stdout = os.Stderr
+		cmd.Stderr = os.Stderr
+		cmd.Env = append(os.Environ(), "MONOGO_ENTRYPOINT="+entry.Path, "MONOGO_ENTRYPOINT_NAME="+entry.Name)
+		if err := cmd.Run(); err != nil {
+			c.Logger.Error("failed to run command", "entrypoint", entry.Path, "error", err)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/brunoluiz/monogo"
+	"github.com/brunoluiz/monogo/git"
+)
+
+type WhoUsesCmd struct {
+	Target      string   `arg:"" help:"Module or package path (e.g., github.com/aws/aws-sdk-go-v2/service/s3)"`
+	Path        string   `help:"Path to the repository" default:"."`
+	Ref         string   `default:"HEAD" help:"Reference to analyze (e.g., refs/heads/main)"`
+	Entrypoints []string `required:"" help:"Entrypoints to analyze, or 'auto' to discover main packages"`
+	Output      string   `help:"Output format: json or text" default:"json" enum:"json,text"`
+}
+
+func (r *WhoUsesCmd) Run(c *Context) error {
+	g, err := git.New(git.WithPath(r.Path))
+	if err != nil {
+		return fmt.Errorf("failed to open git repository: %w", err)
+	}
+
+	entrypoints, opts := entrypointOpts(r.Entrypoints)
+	detector := monogo.NewDetector(entrypoints, c.Logger, g, append(opts, monogo.WithPath(r.Path))...)
+	out, err := detector.WhoUses(c.Context, r.Ref, r.Target)
+	if err != nil {
+		return fmt.Errorf("failed to run who-uses command: %w", err)
+	}
+
+	switch r.Output {
+	case "json":
+		if err := json.NewEncoder(os.Stdout).Encode(out); err != nil {
+			return fmt.Errorf("failed to encode output: %w", err)
+		}
+	case "text":
+		for _, entry := range out.Entrypoints {
+			fmt.Println(entry.Path)
+			for _, chain := range entry.Chains {
+				fmt.Printf("  %s\n", strings.Join(chain, " -> "))
+			}
+		}
+	default:
+		return fmt.Errorf("unknown output format: %s", r.Output)
+	}
+
+	return nil
+}
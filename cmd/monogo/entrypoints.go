@@ -0,0 +1,18 @@
+package main
+
+import "github.com/brunoluiz/monogo"
+
+// autoEntrypoints is the --entrypoints value enabling discovery of main packages
+const autoEntrypoints = "auto"
+
+func isAuto(entrypoints []string) bool {
+	return len(entrypoints) == 1 && entrypoints[0] == autoEntrypoints
+}
+
+// entrypointOpts maps --entrypoints to the detector entrypoints and options, enabling discovery if set to auto
+func entrypointOpts(entrypoints []string) ([]string, []monogo.WithDetectOpt) {
+	if isAuto(entrypoints) {
+		return []string{}, []monogo.WithDetectOpt{monogo.WithDiscover(nil)}
+	}
+	return entrypoints, []monogo.WithDetectOpt{}
+}
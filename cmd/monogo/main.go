@@ -16,9 +16,19 @@ type Context struct {
 }
 
 var cli struct {
-	LogLevel slog.Level `help:"Log level to use for the application." default:"INFO" enum:"DEBUG,INFO,WARN,ERROR"`
-	Detect   DetectCmd  `cmd:"" help:"Detect changed Golang packages based on git changes"`
-	Version  VersionCmd `cmd:"" help:"Return version details"`
+	LogLevel slog.Level  `help:"Log level to use for the application." default:"INFO" enum:"DEBUG,INFO,WARN,ERROR"`
+	Detect   DetectCmd   `cmd:"" help:"Detect changed Golang packages based on git changes"`
+	Affected AffectedCmd `cmd:"" help:"Detect entrypoints affected by an explicit list of changed files, without git"`
+	DiffDirs DiffDirsCmd `cmd:"" help:"Detect changed Golang packages between two directory snapshots, without git"`
+	Snapshot SnapshotCmd `cmd:"" help:"Export the base ref analysis, so detect can use it instead of checking out the base ref"`
+	Watch    WatchCmd    `cmd:"" help:"Watch the working tree, reporting affected entrypoints as files change"`
+	Graph    GraphCmd    `cmd:"" help:"Export the package import graph of the entrypoints in DOT, Mermaid or JSON"`
+	Query    QueryCmd    `cmd:"" help:"Query the package import graph (e.g., 'rdeps(./pkg/shared) intersect entrypoints()')"`
+	Vuln     VulnCmd     `cmd:"" help:"Match entrypoint dependencies against a local OSV vulnerability database"`
+	SBOM     SBOMCmd     `cmd:"" name:"sbom" help:"Generate a CycloneDX or SPDX document per entrypoint"`
+	WhoUses  WhoUsesCmd  `cmd:"" help:"List entrypoints importing a module or package"`
+	Mod      ModCmd      `cmd:"" help:"Inspect go.mod requirements"`
+	Version  VersionCmd  `cmd:"" help:"Return version details"`
 }
 
 func main() {
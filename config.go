@@ -0,0 +1,331 @@
+package monogo
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/brunoluiz/monogo/glob"
+	"github.com/brunoluiz/monogo/mod"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// DefaultConfigFile is the config file looked up at the repository root
+	DefaultConfigFile = ".monogo.yaml"
+	// ConfigVersion is the only supported config file version
+	ConfigVersion = 1
+)
+
+// ConfigOutputs lists the supported output formats
+var ConfigOutputs = []string{"json", "github"}
+
+// Config is the repository config, usually read from .monogo.yaml
+type Config struct {
+	Version     int                `yaml:"version"`
+	Refs        ConfigRefs         `yaml:"refs"`
+	Entrypoints []ConfigEntrypoint `yaml:"entrypoints"`
+	// Libraries are package patterns (eg: ./pkg/sdk/...) detected like entrypoints, but reported separately
+	Libraries     []ConfigEntrypoint `yaml:"libraries"`
+	Discover      *ConfigDiscover    `yaml:"discover"`
+	Ignore        []string           `yaml:"ignore"`
+	Triggers      []string           `yaml:"triggers"`
+	Output        string             `yaml:"output"`
+	ShowUnchanged bool               `yaml:"show_unchanged"`
+	Policies      ConfigPolicies     `yaml:"policies"`
+	Godebug       ConfigGodebug      `yaml:"godebug"`
+	Deploy        ConfigDeploy       `yaml:"deploy"`
+}
+
+type ConfigRefs struct {
+	Base    string `yaml:"base"`
+	Compare string `yaml:"compare"`
+}
+
+type ConfigEntrypoint struct {
+	Path string `yaml:"path"`
+	// Name and Attributes are passed through to the results, so CI matrices can use them (eg: image, team)
+	Name       string            `yaml:"name"`
+	Attributes map[string]string `yaml:"attributes"`
+	// Inputs contains repository relative globs for non-Go files the entrypoint depends on (eg: Dockerfile)
+	Inputs []string `yaml:"inputs"`
+	// Ignore contains repository relative globs excluded from the git diff for this entrypoint only
+	Ignore []string `yaml:"ignore"`
+	// DependsOn contains the paths or names of the entrypoints deployed before this one
+	DependsOn []string `yaml:"depends_on"`
+}
+
+// ConfigDiscover enables entrypoint discovery. Configured entrypoints still apply their settings
+// to discovered ones with the same path.
+type ConfigDiscover struct {
+	// Patterns filter discovered entrypoints. Patterns prefixed with `!` exclude them.
+	Patterns []string `yaml:"patterns"`
+}
+
+type ConfigPolicies struct {
+	GoVersion mod.Policy `yaml:"go_version"`
+	Toolchain mod.Policy `yaml:"toolchain"`
+}
+
+type ConfigGodebug struct {
+	Scope []string `yaml:"scope"`
+}
+
+type ConfigDeploy struct {
+	// Propagate marks entrypoints as changed if an entrypoint they depend on changed
+	Propagate bool `yaml:"propagate"`
+}
+
+// DefaultConfig returns the config used when no config file is present
+func DefaultConfig() Config {
+	return Config{
+		Version:  ConfigVersion,
+		Refs:     ConfigRefs{Base: "refs/heads/main"},
+		Output:   "json",
+		Policies: ConfigPolicies{GoVersion: mod.PolicyAll, Toolchain: mod.PolicyAll},
+	}
+}
+
+// ReadConfig reads and validates the config file. Unset keys keep their default values.
+func ReadConfig(path string) (Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to open config: %w", err)
+	}
+	defer f.Close()
+
+	cfg, err := ParseConfig(f)
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// ParseConfig decodes and validates a config, rejecting unknown keys
+func ParseConfig(r io.Reader) (Config, error) {
+	cfg := DefaultConfig()
+	// Version must be explicitly set, so future versions can change the schema
+	cfg.Version = 0
+
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return Config{}, fmt.Errorf("failed to decode: %w", err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// Validate returns an error describing the first invalid setting
+func (c Config) Validate() error {
+	if c.Version == 0 {
+		return fmt.Errorf("version is required (supported: %d)", ConfigVersion)
+	}
+	if c.Version != ConfigVersion {
+		return fmt.Errorf("unsupported version %d (supported: %d)", c.Version, ConfigVersion)
+	}
+
+	if !slices.Contains(ConfigOutputs, c.Output) {
+		return fmt.Errorf("unknown output %q: must be one of %v", c.Output, ConfigOutputs)
+	}
+	if _, err := mod.ParsePolicy(string(c.Policies.GoVersion)); err != nil {
+		return fmt.Errorf("policies.go_version: %w", err)
+	}
+	if _, err := mod.ParsePolicy(string(c.Policies.Toolchain)); err != nil {
+		return fmt.Errorf("policies.toolchain: %w", err)
+	}
+
+	// Paths and names must be unique across entrypoints and libraries, as their settings are matched by path
+	seen := map[string]bool{}
+	names := map[string]bool{}
+	if err := validateEntrypoints("entrypoints", c.Entrypoints, seen, names); err != nil {
+		return err
+	}
+	if err := validateEntrypoints("libraries", c.Libraries, seen, names); err != nil {
+		return err
+	}
+	if err := c.validateDependsOn(); err != nil {
+		return err
+	}
+
+	if c.Discover != nil {
+		if err := validateGlobs("discover.patterns", c.Discover.Patterns); err != nil {
+			return err
+		}
+	}
+	if err := validateGlobs("ignore", c.Ignore); err != nil {
+		return err
+	}
+	if err := validateGlobs("triggers", c.Triggers); err != nil {
+		return err
+	}
+	return validateGlobs("godebug.scope", c.Godebug.Scope)
+}
+
+func validateEntrypoints(key string, entrypoints []ConfigEntrypoint, seen, names map[string]bool) error {
+	for i, entry := range entrypoints {
+		if strings.TrimSpace(entry.Path) == "" {
+			return fmt.Errorf("%s[%d]: path is required", key, i)
+		}
+		path := glob.Clean(entry.Path)
+		if seen[path] {
+			return fmt.Errorf("%s[%d]: duplicated path %s", key, i, entry.Path)
+		}
+		seen[path] = true
+
+		if entry.Name != "" {
+			if names[entry.Name] {
+				return fmt.Errorf("%s[%d]: duplicated name %s", key, i, entry.Name)
+			}
+			names[entry.Name] = true
+		}
+		for attribute := range entry.Attributes {
+			if attribute == "" || slices.Contains(EntrypointResFields(), attribute) {
+				return fmt.Errorf("%s[%d].attributes: reserved key %q", key, i, attribute)
+			}
+		}
+
+		if err := validateGlobs(fmt.Sprintf("%s[%d].inputs", key, i), entry.Inputs); err != nil {
+			return err
+		}
+		if err := validateGlobs(fmt.Sprintf("%s[%d].ignore", key, i), entry.Ignore); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateDependsOn checks that dependencies refer to other entrypoints, without cycles. Libraries are not
+// deployed, so they can't declare nor be dependencies.
+func (c Config) validateDependsOn() error {
+	for i, library := range c.Libraries {
+		if len(library.DependsOn) > 0 {
+			return fmt.Errorf("libraries[%d].depends_on: only entrypoints can declare dependencies", i)
+		}
+	}
+
+	for i, entry := range c.Entrypoints {
+		for j, dep := range entry.DependsOn {
+			path, ok := c.entrypointPath(dep)
+			switch {
+			case !ok:
+				return fmt.Errorf("entrypoints[%d].depends_on[%d]: unknown entrypoint %s", i, j, dep)
+			case glob.Clean(path) == glob.Clean(entry.Path):
+				return fmt.Errorf("entrypoints[%d].depends_on[%d]: entrypoint depends on itself", i, j)
+			}
+		}
+	}
+
+	_, err := dependsOnLevels(cleanDependsOn(c.dependsOnByEntrypoint()))
+	return err
+}
+
+func validateGlobs(key string, patterns []string) error {
+	for i, pattern := range patterns {
+		if err := glob.Validate(pattern); err != nil {
+			return fmt.Errorf("%s[%d]: %w", key, i, err)
+		}
+	}
+	return nil
+}
+
+// EntrypointPaths returns the path of all configured entrypoints. If discovery is enabled, entrypoints
+// are only known at each ref, so it returns none.
+func (c Config) EntrypointPaths() []string {
+	if c.Discover != nil {
+		return []string{}
+	}
+
+	return configPaths(c.Entrypoints)
+}
+
+// LibraryPaths returns the path of all configured libraries
+func (c Config) LibraryPaths() []string {
+	return configPaths(c.Libraries)
+}
+
+func configPaths(entrypoints []ConfigEntrypoint) []string {
+	paths := make([]string, 0, len(entrypoints))
+	for _, entry := range entrypoints {
+		paths = append(paths, entry.Path)
+	}
+	return paths
+}
+
+// DetectorOpts maps the config to detector options. Options appended afterwards take precedence.
+func (c Config) DetectorOpts() []WithDetectOpt {
+	opts := []WithDetectOpt{
+		WithBaseRef(c.Refs.Base),
+		WithCompareRef(c.Refs.Compare),
+		WithShowUnchanged(c.ShowUnchanged),
+		WithGoVersionPolicy(c.Policies.GoVersion),
+		WithToolchainPolicy(c.Policies.Toolchain),
+		WithGodebugScope(c.Godebug.Scope),
+		WithIgnore(c.Ignore),
+		WithTriggers(c.Triggers),
+		WithInputs(c.globsByEntrypoint(func(e ConfigEntrypoint) []string { return e.Inputs })),
+		WithEntrypointIgnore(c.globsByEntrypoint(func(e ConfigEntrypoint) []string { return e.Ignore })),
+		WithMetadata(c.metadataByEntrypoint()),
+		WithLibraries(c.LibraryPaths()),
+		WithDependsOn(c.dependsOnByEntrypoint()),
+		WithPropagateDependsOn(c.Deploy.Propagate),
+	}
+	if c.Discover != nil {
+		opts = append(opts, WithDiscover(c.Discover.Patterns))
+	}
+	return opts
+}
+
+// globsByEntrypoint returns the globs picked from each entrypoint and library, by path
+func (c Config) globsByEntrypoint(pick func(ConfigEntrypoint) []string) map[string][]string {
+	globs := map[string][]string{}
+	for _, entry := range slices.Concat(c.Entrypoints, c.Libraries) {
+		if patterns := pick(entry); len(patterns) > 0 {
+			globs[entry.Path] = patterns
+		}
+	}
+	return globs
+}
+
+// metadataByEntrypoint returns the name and attributes of each entrypoint and library setting any, by path
+func (c Config) metadataByEntrypoint() map[string]EntrypointMetadata {
+	metadata := map[string]EntrypointMetadata{}
+	for _, entry := range slices.Concat(c.Entrypoints, c.Libraries) {
+		if entry.Name != "" || len(entry.Attributes) > 0 {
+			metadata[entry.Path] = EntrypointMetadata{Name: entry.Name, Attributes: entry.Attributes}
+		}
+	}
+	return metadata
+}
+
+// dependsOnByEntrypoint returns the paths of the entrypoints each entrypoint depends on, by path. Names are
+// resolved to paths, while unknown entrypoints are kept as they are.
+func (c Config) dependsOnByEntrypoint() map[string][]string {
+	deps := map[string][]string{}
+	for _, entry := range c.Entrypoints {
+		for _, dep := range entry.DependsOn {
+			path, ok := c.entrypointPath(dep)
+			if !ok {
+				path = dep
+			}
+			deps[entry.Path] = append(deps[entry.Path], path)
+		}
+	}
+	return deps
+}
+
+// entrypointPath returns the path of the configured entrypoint with the given name or path
+func (c Config) entrypointPath(ref string) (string, bool) {
+	for _, entry := range c.Entrypoints {
+		if entry.Name == ref || glob.Clean(entry.Path) == glob.Clean(ref) {
+			return entry.Path, true
+		}
+	}
+	return "", false
+}
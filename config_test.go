@@ -0,0 +1,201 @@
+package monogo_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/brunoluiz/monogo"
+	"github.com/brunoluiz/monogo/mod"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected monogo.Config
+		err      string
+	}{
+		{
+			name: "should parse all settings",
+			input: `
+version: 1
+refs:
+  base: refs/heads/develop
+  compare: HEAD
+entrypoints:
+  - path: ./cmd/hello
+    inputs: [cmd/hello/Dockerfile, "deploy/hello/**"]
+  - path: ./cmd/foo
+    ignore: ["cmd/foo/mocks/**"]
+ignore: ["**/*.md"]
+triggers: [Makefile]
+output: github
+show_unchanged: true
+policies:
+  go_version: minor-only
+godebug:
+  scope: [./cmd/foo]
+`,
+			expected: monogo.Config{
+				Version: 1,
+				Refs:    monogo.ConfigRefs{Base: "refs/heads/develop", Compare: "HEAD"},
+				Entrypoints: []monogo.ConfigEntrypoint{
+					{Path: "./cmd/hello", Inputs: []string{"cmd/hello/Dockerfile", "deploy/hello/**"}},
+					{Path: "./cmd/foo", Ignore: []string{"cmd/foo/mocks/**"}},
+				},
+				Ignore:        []string{"**/*.md"},
+				Triggers:      []string{"Makefile"},
+				Output:        "github",
+				ShowUnchanged: true,
+				Policies:      monogo.ConfigPolicies{GoVersion: mod.PolicyMinorOnly, Toolchain: mod.PolicyAll},
+				Godebug:       monogo.ConfigGodebug{Scope: []string{"./cmd/foo"}},
+			},
+		},
+		{
+			name:     "should keep defaults for unset keys",
+			input:    "version: 1\n",
+			expected: monogo.DefaultConfig(),
+		},
+		{
+			name:  "should parse discover block",
+			input: "version: 1\ndiscover:\n  patterns: [\"services/*/cmd/*\", \"!**/tools/**\"]\n",
+			expected: func() monogo.Config {
+				cfg := monogo.DefaultConfig()
+				cfg.Discover = &monogo.ConfigDiscover{Patterns: []string{"services/*/cmd/*", "!**/tools/**"}}
+				return cfg
+			}(),
+		},
+		{
+			name:  "should parse entrypoint metadata",
+			input: "version: 1\nentrypoints:\n  - path: ./cmd/hello\n    name: hello\n    attributes:\n      image: ghcr.io/org/hello\n      cpus: 2\n",
+			expected: func() monogo.Config {
+				cfg := monogo.DefaultConfig()
+				cfg.Entrypoints = []monogo.ConfigEntrypoint{{
+					Path:       "./cmd/hello",
+					Name:       "hello",
+					Attributes: map[string]string{"image": "ghcr.io/org/hello", "cpus": "2"},
+				}}
+				return cfg
+			}(),
+		},
+		{
+			name:  "should parse libraries",
+			input: "version: 1\nlibraries:\n  - path: ./pkg/sdk/...\n    name: sdk\n",
+			expected: func() monogo.Config {
+				cfg := monogo.DefaultConfig()
+				cfg.Libraries = []monogo.ConfigEntrypoint{{Path: "./pkg/sdk/...", Name: "sdk"}}
+				return cfg
+			}(),
+		},
+		{
+			name:  "should parse entrypoint dependencies",
+			input: "version: 1\nentrypoints:\n  - path: ./cmd/migrate\n    name: migrate\n  - path: ./cmd/api\n    depends_on: [migrate]\ndeploy:\n  propagate: true\n",
+			expected: func() monogo.Config {
+				cfg := monogo.DefaultConfig()
+				cfg.Entrypoints = []monogo.ConfigEntrypoint{
+					{Path: "./cmd/migrate", Name: "migrate"},
+					{Path: "./cmd/api", DependsOn: []string{"migrate"}},
+				}
+				cfg.Deploy = monogo.ConfigDeploy{Propagate: true}
+				return cfg
+			}(),
+		},
+		{
+			name:  "should require version",
+			input: "output: json\n",
+			err:   "version is required",
+		},
+		{
+			name:  "should reject unsupported versions",
+			input: "version: 2\n",
+			err:   "unsupported version 2",
+		},
+		{
+			name:  "should reject unknown keys",
+			input: "version: 1\nentrypoint:\n  - path: ./cmd/hello\n",
+			err:   "line 2: field entrypoint not found",
+		},
+		{
+			name:  "should reject unknown outputs",
+			input: "version: 1\noutput: yaml\n",
+			err:   `unknown output "yaml"`,
+		},
+		{
+			name:  "should reject unknown policies",
+			input: "version: 1\npolicies:\n  toolchain: sometimes\n",
+			err:   `policies.toolchain: unknown version policy "sometimes"`,
+		},
+		{
+			name:  "should reject duplicated entrypoints",
+			input: "version: 1\nentrypoints:\n  - path: ./cmd/hello\n  - path: cmd/hello\n",
+			err:   "entrypoints[1]: duplicated path cmd/hello",
+		},
+		{
+			name:  "should reject duplicated entrypoint names",
+			input: "version: 1\nentrypoints:\n  - path: ./cmd/hello\n    name: hello\n  - path: ./cmd/foo\n    name: hello\n",
+			err:   "entrypoints[1]: duplicated name hello",
+		},
+		{
+			name:  "should reject reserved attributes",
+			input: "version: 1\nentrypoints:\n  - path: ./cmd/hello\n    attributes:\n      changed: \"yes\"\n",
+			err:   `entrypoints[0].attributes: reserved key "changed"`,
+		},
+		{
+			name:  "should reject libraries duplicating entrypoints",
+			input: "version: 1\nentrypoints:\n  - path: ./pkg/sdk\nlibraries:\n  - path: pkg/sdk\n",
+			err:   "libraries[0]: duplicated path pkg/sdk",
+		},
+		{
+			name:  "should reject invalid globs",
+			input: "version: 1\nentrypoints:\n  - path: ./cmd/hello\n    inputs: [\"deploy/[\"]\n",
+			err:   "entrypoints[0].inputs[0]: invalid pattern",
+		},
+		{
+			name:  "should reject unknown dependencies",
+			input: "version: 1\nentrypoints:\n  - path: ./cmd/api\n    depends_on: [migrate]\n",
+			err:   "entrypoints[0].depends_on[0]: unknown entrypoint migrate",
+		},
+		{
+			name:  "should reject entrypoints depending on themselves",
+			input: "version: 1\nentrypoints:\n  - path: ./cmd/api\n    name: api\n    depends_on: [api]\n",
+			err:   "entrypoints[0].depends_on[0]: entrypoint depends on itself",
+		},
+		{
+			name:  "should reject dependency cycles",
+			input: "version: 1\nentrypoints:\n  - path: ./cmd/api\n    depends_on: [./cmd/worker]\n  - path: ./cmd/worker\n    depends_on: [./cmd/migrate]\n  - path: ./cmd/migrate\n    depends_on: [./cmd/api]\n",
+			err:   "depends_on cycle: cmd/api -> cmd/worker -> cmd/migrate -> cmd/api",
+		},
+		{
+			name:  "should reject library dependencies",
+			input: "version: 1\nentrypoints:\n  - path: ./cmd/api\nlibraries:\n  - path: ./pkg/sdk/...\n    depends_on: [./cmd/api]\n",
+			err:   "libraries[0].depends_on: only entrypoints can declare dependencies",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := monogo.ParseConfig(strings.NewReader(tt.input))
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, cfg)
+		})
+	}
+}
+
+func TestReadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), monogo.DefaultConfigFile)
+	require.NoError(t, os.WriteFile(path, []byte("version: 1\nentrypoints:\n  - path: ./cmd/hello\n"), 0o600))
+
+	cfg, err := monogo.ReadConfig(path)
+	require.NoError(t, err)
+	require.Equal(t, []string{"./cmd/hello"}, cfg.EntrypointPaths())
+
+	_, err = monogo.ReadConfig(filepath.Join(t.TempDir(), "missing.yaml"))
+	require.Error(t, err)
+}
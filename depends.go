@@ -0,0 +1,132 @@
+package monogo
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/brunoluiz/monogo/glob"
+	"github.com/samber/lo"
+)
+
+// dependsOn propagates changes to the entrypoints depending on changed ones, if enabled, and returns the changed
+// entrypoints grouped in deploy waves. Propagated entrypoints must be within the targets, so they exist in the tree.
+func (r *Detector) dependsOn(results []DetectEntrypointRes, targets []string) ([]DetectEntrypointRes, [][]string, error) {
+	deps := cleanDependsOn(r.DependsOn)
+	levels, err := dependsOnLevels(deps)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if r.PropagateDependsOn {
+		// Entrypoints are visited by level, so changes propagated to dependencies are seen by their dependents
+		ordered := slices.Clone(targets)
+		slices.SortStableFunc(ordered, func(a, b string) int { return levels[glob.Clean(a)] - levels[glob.Clean(b)] })
+
+		changed := map[string]bool{}
+		for _, res := range results {
+			if res.Changed && res.Status != EntrypointRemoved {
+				changed[glob.Clean(res.Path)] = true
+			}
+		}
+		for _, entry := range ordered {
+			path := glob.Clean(entry)
+			changedDeps := lo.Filter(deps[path], func(dep string, _ int) bool { return changed[dep] })
+			if changed[path] || len(changedDeps) == 0 {
+				continue
+			}
+
+			changed[path] = true
+			reasons := []ChangeReason{DependsOnChangedReason}
+			evidence := r.evidence(entry, reasons, explainInfo{dependsOn: changedDeps})
+			i := slices.IndexFunc(results, func(res DetectEntrypointRes) bool { return glob.Clean(res.Path) == path })
+			if i >= 0 {
+				results[i].Changed = true
+				results[i].Reasons = append(results[i].Reasons, reasons...)
+				results[i].Evidence = append(results[i].Evidence, evidence...)
+				continue
+			}
+			results = append(results, DetectEntrypointRes{
+				Path:         entry,
+				Changed:      true,
+				Reasons:      reasons,
+				Dependencies: []DetectDependencyRes{},
+				Inputs:       []string{},
+				Ignored:      []string{},
+				Evidence:     evidence,
+			})
+		}
+	}
+
+	entrypoints, _ := r.splitLibraries(results)
+	deployed := lo.FilterMap(entrypoints, func(res DetectEntrypointRes, _ int) (string, bool) {
+		return res.Path, res.Changed && res.Status != EntrypointRemoved
+	})
+	return results, deployWaves(levels, deployed), nil
+}
+
+// cleanDependsOn returns the dependencies with cleaned paths, so they can be matched against results
+func cleanDependsOn(dependsOn map[string][]string) map[string][]string {
+	deps := map[string][]string{}
+	for entry, entryDeps := range dependsOn {
+		path := glob.Clean(entry)
+		deps[path] = lo.Uniq(append(deps[path], lo.Map(entryDeps, func(dep string, _ int) string { return glob.Clean(dep) })...))
+	}
+	return deps
+}
+
+// dependsOnLevels returns the deploy level of each entrypoint: entrypoints without dependencies are at level 0,
+// while others are one level after their last dependency. It fails if dependencies have cycles.
+func dependsOnLevels(deps map[string][]string) (map[string]int, error) {
+	levels := map[string]int{}
+	visiting := []string{}
+	var visit func(entry string) (int, error)
+	visit = func(entry string) (int, error) {
+		if level, ok := levels[entry]; ok {
+			return level, nil
+		}
+		if i := slices.Index(visiting, entry); i >= 0 {
+			cycle := append(slices.Clone(visiting[i:]), entry)
+			return 0, fmt.Errorf("depends_on cycle: %s", strings.Join(cycle, " -> "))
+		}
+
+		visiting = append(visiting, entry)
+		level := 0
+		for _, dep := range deps[entry] {
+			depLevel, err := visit(dep)
+			if err != nil {
+				return 0, err
+			}
+			level = max(level, depLevel+1)
+		}
+		visiting = visiting[:len(visiting)-1]
+		levels[entry] = level
+		return level, nil
+	}
+
+	// Entrypoints are sorted, so the same cycle is reported on every run
+	entries := lo.Keys(deps)
+	slices.Sort(entries)
+	for _, entry := range entries {
+		if _, err := visit(entry); err != nil {
+			return nil, err
+		}
+	}
+	return levels, nil
+}
+
+// deployWaves groups the entrypoints by level, skipping empty ones. Entrypoints only depend on the ones in
+// previous waves, even if the entrypoints between them are not deployed.
+func deployWaves(levels map[string]int, entrypoints []string) [][]string {
+	byLevel := lo.GroupBy(lo.Uniq(entrypoints), func(entry string) int { return levels[glob.Clean(entry)] })
+	keys := lo.Keys(byLevel)
+	slices.Sort(keys)
+
+	waves := make([][]string, 0, len(keys))
+	for _, level := range keys {
+		wave := byLevel[level]
+		slices.Sort(wave)
+		waves = append(waves, wave)
+	}
+	return waves
+}
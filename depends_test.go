@@ -0,0 +1,99 @@
+package monogo_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/brunoluiz/monogo"
+	xgit "github.com/brunoluiz/monogo/git"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetector_Run_DependsOn(t *testing.T) {
+	// app3 is deployed after app1, which is deployed after app2
+	dependsOn := map[string][]string{"cmd/app1": {"cmd/app2"}, "./cmd/app3": {"./cmd/app1"}}
+
+	tests := []struct {
+		name   string
+		opts   []monogo.WithDetectOpt
+		err    string
+		assert func(t *testing.T, res monogo.DetectRes)
+	}{
+		{
+			name: "should order changed entrypoints in waves, through unchanged ones",
+			opts: []monogo.WithDetectOpt{monogo.WithDependsOn(dependsOn)},
+			assert: func(t *testing.T, res monogo.DetectRes) {
+				require.Equal(t, []string{"app2", "app3"}, entrypointPaths(res.Entrypoints))
+				require.Equal(t, [][]string{{"cmd/app2"}, {"cmd/app3"}}, res.Waves)
+			},
+		},
+		{
+			name: "should propagate changes to dependents",
+			opts: []monogo.WithDetectOpt{
+				monogo.WithDependsOn(dependsOn),
+				monogo.WithPropagateDependsOn(true),
+				monogo.WithExplain(true),
+			},
+			assert: func(t *testing.T, res monogo.DetectRes) {
+				require.Equal(t, []string{"app1", "app2", "app3"}, entrypointPaths(res.Entrypoints))
+				require.Equal(t, [][]string{{"cmd/app2"}, {"cmd/app1"}, {"cmd/app3"}}, res.Waves)
+
+				app1 := findEntrypoint(res.Entrypoints, "cmd/app1")
+				require.Equal(t, []monogo.ChangeReason{monogo.DependsOnChangedReason}, app1.Reasons)
+				require.Equal(t, []monogo.DetectEvidenceRes{
+					{Reason: monogo.DependsOnChangedReason, Entrypoints: []string{"cmd/app2"}},
+				}, app1.Evidence)
+
+				// app3 changed by itself, so its reasons are kept as they are
+				app3 := findEntrypoint(res.Entrypoints, "cmd/app3")
+				require.NotContains(t, app3.Reasons, monogo.DependsOnChangedReason)
+			},
+		},
+		{
+			name: "should update unchanged entrypoints when shown",
+			opts: []monogo.WithDetectOpt{
+				monogo.WithDependsOn(dependsOn),
+				monogo.WithPropagateDependsOn(true),
+				monogo.WithShowUnchanged(true),
+			},
+			assert: func(t *testing.T, res monogo.DetectRes) {
+				require.Len(t, res.Entrypoints, 3)
+				require.True(t, findEntrypoint(res.Entrypoints, "cmd/app1").Changed)
+			},
+		},
+		{
+			name: "should fail on cycles",
+			opts: []monogo.WithDetectOpt{monogo.WithDependsOn(map[string][]string{
+				"cmd/app1": {"cmd/app2"},
+				"cmd/app2": {"./cmd/app1"},
+			})},
+			err: "depends_on cycle: cmd/app1 -> cmd/app2 -> cmd/app1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir, w, b := setupTestRepo(t)
+			commitFile(t, w, "pkg/pkgB/extra.go", "package pkgB\n")
+
+			g, err := xgit.New(xgit.WithPath(tmpDir))
+			require.NoError(t, err)
+			opts := append([]monogo.WithDetectOpt{
+				monogo.WithPath(tmpDir),
+				monogo.WithBaseRef(string(plumbing.NewBranchReferenceName("main"))),
+				monogo.WithCompareRef(string(b)),
+			}, tt.opts...)
+			d := monogo.NewDetector([]string{"cmd/app1", "cmd/app2", "cmd/app3"}, slog.Default(), g, opts...)
+
+			res, err := d.Run(context.Background())
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			tt.assert(t, res)
+		})
+	}
+}
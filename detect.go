@@ -2,14 +2,23 @@ package monogo
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"log/slog"
+	"os"
+	"path"
 	"path/filepath"
+	"reflect"
+	"slices"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/brunoluiz/monogo/diskcache"
 	"github.com/brunoluiz/monogo/git"
+	"github.com/brunoluiz/monogo/glob"
 	"github.com/brunoluiz/monogo/mod"
 	"github.com/brunoluiz/monogo/walker"
 	"github.com/brunoluiz/monogo/walker/hook"
@@ -25,14 +34,49 @@ const (
 	CreatedDeletedFilesReasons ChangeReason = "files created/deleted"
 	DependenciesChangedReason  ChangeReason = "dependencies changed"
 	GoVersionChangedReason     ChangeReason = "go version changed"
-	NoGitChangesReason         ChangeReason = "no git changes"
+	GoToolchainChangedReason   ChangeReason = "go toolchain changed"
+	GodebugChangedReason       ChangeReason = "godebug changed"
+	ToolsChangedReason         ChangeReason = "tools changed"
+	GlobalTriggerReason        ChangeReason = "global trigger"
+	ExtraInputsChangedReason   ChangeReason = "extra inputs changed"
+	// ExtraInputsCreatedDeletedReason is used when the files matching the extra inputs differ between refs,
+	// either because files were created/deleted or the globs changed
+	ExtraInputsCreatedDeletedReason ChangeReason = "extra inputs created/deleted"
+	EntrypointAddedReason           ChangeReason = "entrypoint added"
+	EntrypointRemovedReason         ChangeReason = "entrypoint removed"
+	EntrypointMovedReason           ChangeReason = "entrypoint moved"
+	NoGitChangesReason              ChangeReason = "no git changes"
+	// DependsOnChangedReason is used when an entrypoint declared as a dependency changed, if propagation is enabled
+	DependsOnChangedReason ChangeReason = "depends on changed entrypoint"
+)
+
+// EntrypointStatus tells if an entrypoint was added, removed or moved between refs. It is empty if the
+// entrypoint exists in both refs.
+type EntrypointStatus string
+
+const (
+	EntrypointAdded   EntrypointStatus = "added"
+	EntrypointRemoved EntrypointStatus = "removed"
+	EntrypointMoved   EntrypointStatus = "moved"
 )
 
 type DetectRes struct {
-	Changed     bool                  `json:"changed"`
-	Git         DetectGitRes          `json:"git"`
+	// Changed is true if any entrypoint changed. Libraries are reported by LibrariesChanged.
+	Changed          bool `json:"changed"`
+	LibrariesChanged bool `json:"libraries_changed"`
+	// Reasons contains repository wide changes, which are not tied to any entrypoint
+	Reasons []ChangeReason `json:"reasons"`
+	// Triggers contains the changed files matching the global triggers
+	Triggers []string     `json:"triggers"`
+	Git      DetectGitRes `json:"git"`
+	// Mod is left empty when global triggers match, as the base ref go.mod is not read
+	Mod         DetectModRes          `json:"mod"`
 	Stats       DetectStatsRes        `json:"stats"`
 	Entrypoints []DetectEntrypointRes `json:"entrypoints"`
+	// Libraries contains the results for library targets, which have the same shape as entrypoints
+	Libraries []DetectEntrypointRes `json:"libraries"`
+	// Waves contains the changed entrypoint paths in deploy order: entrypoints only depend on previous waves
+	Waves [][]string `json:"waves"`
 }
 
 type DetectGitRes struct {
@@ -46,6 +90,8 @@ type DetectGitChangesRes struct {
 	Updated  DetectFileTypeRes `json:"updated"`
 	Deleted  DetectFileTypeRes `json:"deleted"`
 	Impacted DetectFileTypeRes `json:"impacted"`
+	// Ignored contains the changed files matching the ignore globs, which are excluded from the other buckets
+	Ignored DetectFileTypeRes `json:"ignored"`
 }
 
 type DetectFileTypeRes struct {
@@ -53,16 +99,145 @@ type DetectFileTypeRes struct {
 	Go  []string `json:"go"`
 }
 
+type DetectModRes struct {
+	Godebug      []string              `json:"godebug"`
+	Tools        DetectToolsRes        `json:"tools"`
+	Dependencies []DetectDependencyRes `json:"dependencies"`
+}
+
+type DetectDependencyRes struct {
+	Path string       `json:"path"`
+	Old  string       `json:"old"`
+	New  string       `json:"new"`
+	Bump mod.BumpType `json:"bump"`
+}
+
+type DetectToolsRes struct {
+	Added   []string `json:"added"`
+	Deleted []string `json:"deleted"`
+}
+
 type DetectStatsRes struct {
 	StartedAt time.Time     `json:"started_at"`
 	EndedAt   time.Time     `json:"ended_at"`
 	Duration  time.Duration `json:"duration"`
 }
 
+// end sets the end time and the duration since the start, in milliseconds
+func (s *DetectStatsRes) end() {
+	s.EndedAt = time.Now()
+	s.Duration = s.EndedAt.Sub(s.StartedAt) / time.Millisecond
+}
+
 type DetectEntrypointRes struct {
-	Path    string         `json:"path"`
-	Changed bool           `json:"changed"`
-	Reasons []ChangeReason `json:"reasons"`
+	Path string `json:"path"`
+	// Name is set from the entrypoint metadata
+	Name   string           `json:"name,omitempty"`
+	Status EntrypointStatus `json:"status,omitempty"`
+	// MovedFrom contains the base ref path of moved entrypoints
+	MovedFrom string         `json:"moved_from,omitempty"`
+	Changed   bool           `json:"changed"`
+	Reasons   []ChangeReason `json:"reasons"`
+	// Dependencies contains the changed modules imported by the entrypoint
+	Dependencies []DetectDependencyRes `json:"dependencies"`
+	// Inputs contains the files matching the entrypoint extra inputs in the compare ref
+	Inputs []string `json:"inputs"`
+	// Ignored contains the changed files matching the entrypoint ignore globs
+	Ignored []string `json:"ignored"`
+	// Triggers contains the changed files matching the global triggers
+	Triggers []string `json:"triggers,omitempty"`
+	// Evidence contains what caused each reason, in the same order. Only set in explain mode.
+	Evidence []DetectEvidenceRes `json:"evidence,omitempty"`
+	// Attributes are set from the entrypoint metadata, and inlined in the JSON output (eg: `matrix.entrypoint.image`)
+	Attributes map[string]string `json:"-"`
+}
+
+// detectEntrypointRes has the same fields, without the custom JSON encoding
+type detectEntrypointRes DetectEntrypointRes
+
+// MarshalJSON inlines the attributes alongside the other fields
+func (e DetectEntrypointRes) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(detectEntrypointRes(e))
+	if err != nil || len(e.Attributes) == 0 {
+		return data, err
+	}
+
+	attributes, err := json.Marshal(e.Attributes)
+	if err != nil {
+		return nil, err
+	}
+
+	// Both are JSON objects, so they are merged by joining their fields: `{"path":...}` + `{"image":...}`
+	return slices.Concat(data[:len(data)-1], []byte(","), attributes[1:]), nil
+}
+
+// UnmarshalJSON reads unknown fields as attributes
+func (e *DetectEntrypointRes) UnmarshalJSON(data []byte) error {
+	var res detectEntrypointRes
+	if err := json.Unmarshal(data, &res); err != nil {
+		return err
+	}
+
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	for key, value := range fields {
+		if slices.Contains(EntrypointResFields(), key) {
+			continue
+		}
+
+		var attribute string
+		if err := json.Unmarshal(value, &attribute); err != nil {
+			return fmt.Errorf("invalid attribute %s: %w", key, err)
+		}
+		if res.Attributes == nil {
+			res.Attributes = map[string]string{}
+		}
+		res.Attributes[key] = attribute
+	}
+
+	*e = DetectEntrypointRes(res)
+	return nil
+}
+
+// EntrypointResFields returns the JSON fields of DetectEntrypointRes, which can't be used as attributes
+func EntrypointResFields() []string {
+	t := reflect.TypeFor[DetectEntrypointRes]()
+	fields := make([]string, 0, t.NumField())
+	for i := range t.NumField() {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields = append(fields, name)
+		}
+	}
+	return fields
+}
+
+// EntrypointMetadata is passed through to the results, so CI matrices can use it
+type EntrypointMetadata struct {
+	Name       string
+	Attributes map[string]string
+}
+
+// DetectEvidenceRes contains what caused a reason. Paths are relative to the repository.
+type DetectEvidenceRes struct {
+	Reason ChangeReason `json:"reason"`
+	// Files contains the changed files, with the package they belong to and its import chain from the entrypoint
+	Files []DetectFileEvidenceRes `json:"files,omitempty"`
+	// Modules contains the changed modules imported by the entrypoint
+	Modules []DetectDependencyRes `json:"modules,omitempty"`
+	// Created and Deleted contain the files only found in the compare or in the base ref
+	Created []string `json:"created,omitempty"`
+	Deleted []string `json:"deleted,omitempty"`
+	// Entrypoints contains the changed entrypoints the entrypoint depends on
+	Entrypoints []string `json:"entrypoints,omitempty"`
+}
+
+type DetectFileEvidenceRes struct {
+	Path    string   `json:"path"`
+	Package string   `json:"package,omitempty"`
+	Chain   []string `json:"chain,omitempty"`
 }
 
 type Detector struct {
@@ -73,15 +248,70 @@ type Detector struct {
 	Logger        *slog.Logger
 	Git           *git.Git
 	ShowUnchanged bool
+	// GoVersionPolicy defines which go directive changes mark all entrypoints as changed
+	GoVersionPolicy mod.Policy
+	// ToolchainPolicy defines which toolchain changes mark all entrypoints as changed
+	ToolchainPolicy mod.Policy
+	// GodebugScope contains the entrypoint globs affected by godebug changes. All entrypoints are affected if empty.
+	GodebugScope []string
+	// Ignore contains globs for files excluded from the git diff
+	Ignore []string
+	// EntrypointIgnore contains globs for files excluded from the git diff, by entrypoint path.
+	// They are applied on top of Ignore.
+	EntrypointIgnore map[string][]string
+	// Triggers contains globs for files marking all entrypoints as changed
+	Triggers []string
+	// Inputs contains globs for non-Go files, by entrypoint path, marking it as changed
+	Inputs map[string][]string
+	// ConfigFile is read at the base and compare refs, if set, so changes to the extra inputs globs are considered
+	ConfigFile string
+	// Discover replaces the entrypoints by the main packages found at each ref
+	Discover bool
+	// DiscoverPatterns contains globs filtering discovered entrypoints. Patterns prefixed with `!` exclude them.
+	DiscoverPatterns []string
+	// Explain attaches the evidence of each reason to the entrypoints
+	Explain bool
+	// Metadata contains the metadata passed through to the results, by entrypoint path
+	Metadata map[string]EntrypointMetadata
+	// Libraries contains package patterns (eg: `./pkg/sdk/...`) detected like entrypoints, but reported separately
+	Libraries []string
+	// Source provides the trees compared by Run, defaulting to the git repository
+	Source Source
+	// Cache stores the packages loaded from each tree, if the source identifies trees by content
+	Cache *diskcache.Cache
+	// BaseSnapshot replaces the base ref analysis, so the base ref is not checked out
+	BaseSnapshot *Snapshot
+	// DependsOn contains the entrypoints each entrypoint depends on, by entrypoint path. They order the deploy waves.
+	DependsOn map[string][]string
+	// PropagateDependsOn marks entrypoints as changed if an entrypoint they depend on changed
+	PropagateDependsOn bool
 }
 
 type WithDetectOpt func(*detectorConfig)
 
 type detectorConfig struct {
-	path          string
-	baseRef       string
-	compareRef    string
-	showUnchanged bool
+	path            string
+	baseRef         string
+	compareRef      string
+	showUnchanged   bool
+	goVersionPolicy mod.Policy
+	toolchainPolicy mod.Policy
+	godebugScope    []string
+	ignore          []string
+	entryIgnore     map[string][]string
+	triggers        []string
+	inputs          map[string][]string
+	configFile      string
+	discover        bool
+	discoverGlobs   []string
+	explain         bool
+	metadata        map[string]EntrypointMetadata
+	libraries       []string
+	source          Source
+	cache           *diskcache.Cache
+	baseSnapshot    *Snapshot
+	dependsOn       map[string][]string
+	propagate       bool
 }
 
 func WithPath(path string) func(*detectorConfig) {
@@ -108,6 +338,121 @@ func WithShowUnchanged(show bool) func(*detectorConfig) {
 	}
 }
 
+func WithGoVersionPolicy(policy mod.Policy) func(*detectorConfig) {
+	return func(d *detectorConfig) {
+		d.goVersionPolicy = policy
+	}
+}
+
+func WithToolchainPolicy(policy mod.Policy) func(*detectorConfig) {
+	return func(d *detectorConfig) {
+		d.toolchainPolicy = policy
+	}
+}
+
+func WithGodebugScope(scope []string) func(*detectorConfig) {
+	return func(d *detectorConfig) {
+		d.godebugScope = scope
+	}
+}
+
+func WithIgnore(globs []string) func(*detectorConfig) {
+	return func(d *detectorConfig) {
+		d.ignore = globs
+	}
+}
+
+func WithEntrypointIgnore(ignore map[string][]string) func(*detectorConfig) {
+	return func(d *detectorConfig) {
+		d.entryIgnore = ignore
+	}
+}
+
+func WithTriggers(globs []string) func(*detectorConfig) {
+	return func(d *detectorConfig) {
+		d.triggers = globs
+	}
+}
+
+func WithInputs(inputs map[string][]string) func(*detectorConfig) {
+	return func(d *detectorConfig) {
+		d.inputs = inputs
+	}
+}
+
+// WithConfigFile sets the config file to read at the base and compare refs, so changes to the extra inputs globs are considered
+func WithConfigFile(path string) func(*detectorConfig) {
+	return func(d *detectorConfig) {
+		d.configFile = path
+	}
+}
+
+// WithDiscover enables entrypoint discovery, filtered by the given patterns
+func WithDiscover(patterns []string) func(*detectorConfig) {
+	return func(d *detectorConfig) {
+		d.discover = true
+		d.discoverGlobs = patterns
+	}
+}
+
+// WithExplain attaches the evidence of each reason to the entrypoints
+func WithExplain(explain bool) func(*detectorConfig) {
+	return func(d *detectorConfig) {
+		d.explain = explain
+	}
+}
+
+// WithMetadata sets the metadata passed through to the results, by entrypoint path
+func WithMetadata(metadata map[string]EntrypointMetadata) func(*detectorConfig) {
+	return func(d *detectorConfig) {
+		d.metadata = metadata
+	}
+}
+
+// WithLibraries sets package patterns (eg: `./pkg/sdk/...`) detected like entrypoints, but reported separately
+func WithLibraries(patterns []string) func(*detectorConfig) {
+	return func(d *detectorConfig) {
+		d.libraries = patterns
+	}
+}
+
+// WithSource replaces the git repository as the provider of the trees compared by Run (eg: a patch)
+func WithSource(source Source) func(*detectorConfig) {
+	return func(d *detectorConfig) {
+		d.source = source
+	}
+}
+
+// WithCache stores the packages loaded from each tree in the cache, so later runs on the same trees skip
+// loading them. It is only used with sources identifying trees by content, such as git.
+func WithCache(c *diskcache.Cache) func(*detectorConfig) {
+	return func(d *detectorConfig) {
+		d.cache = c
+	}
+}
+
+// WithBaseSnapshot uses the snapshot, exported by Snapshot, as the base ref analysis. The compare ref is diffed
+// against the snapshot files, so the base ref is neither checked out nor needed in the repository.
+func WithBaseSnapshot(s *Snapshot) func(*detectorConfig) {
+	return func(d *detectorConfig) {
+		d.baseSnapshot = s
+	}
+}
+
+// WithDependsOn sets the entrypoints each entrypoint depends on, by entrypoint path (eg: migrations before the API)
+func WithDependsOn(dependsOn map[string][]string) func(*detectorConfig) {
+	return func(d *detectorConfig) {
+		d.dependsOn = dependsOn
+	}
+}
+
+// WithPropagateDependsOn marks entrypoints as changed if an entrypoint they depend on changed
+func WithPropagateDependsOn(propagate bool) func(*detectorConfig) {
+	return func(d *detectorConfig) {
+		d.propagate = propagate
+	}
+}
+
 func NewDetector(
 	entrypoints []string,
 	logger *slog.Logger,
@@ -115,31 +460,64 @@ func NewDetector(
 	opts ...WithDetectOpt,
 ) *Detector {
 	cfg := detectorConfig{
-		baseRef: "refs/heads/main",
-		path:    ".",
+		baseRef:         "refs/heads/main",
+		path:            ".",
+		goVersionPolicy: mod.PolicyAll,
+		toolchainPolicy: mod.PolicyAll,
 	}
 	for _, opt := range opts {
 		opt(&cfg)
 	}
+	if cfg.source == nil && g != nil {
+		cfg.source = gitSource{git: g, path: cfg.path}
+	}
+	if cfg.source != nil && cfg.baseSnapshot != nil {
+		cfg.source = snapshotSource{Source: cfg.source, snapshot: cfg.baseSnapshot}
+	}
 
 	return &Detector{
-		Path:          cfg.path,
-		BaseRef:       cfg.baseRef,
-		CompareRef:    cfg.compareRef,
-		Entrypoints:   entrypoints,
-		Logger:        logger,
-		Git:           g,
-		ShowUnchanged: cfg.showUnchanged,
+		Path:               cfg.path,
+		BaseRef:            cfg.baseRef,
+		CompareRef:         cfg.compareRef,
+		Entrypoints:        entrypoints,
+		Logger:             logger,
+		Git:                g,
+		ShowUnchanged:      cfg.showUnchanged,
+		GoVersionPolicy:    cfg.goVersionPolicy,
+		ToolchainPolicy:    cfg.toolchainPolicy,
+		GodebugScope:       cfg.godebugScope,
+		Ignore:             cfg.ignore,
+		EntrypointIgnore:   cfg.entryIgnore,
+		Triggers:           cfg.triggers,
+		Inputs:             cfg.inputs,
+		ConfigFile:         cfg.configFile,
+		Discover:           cfg.discover,
+		DiscoverPatterns:   cfg.discoverGlobs,
+		Explain:            cfg.explain,
+		Metadata:           cfg.metadata,
+		Libraries:          cfg.libraries,
+		Source:             cfg.source,
+		Cache:              cfg.cache,
+		BaseSnapshot:       cfg.baseSnapshot,
+		DependsOn:          cfg.dependsOn,
+		PropagateDependsOn: cfg.propagate,
 	}
 }
 
 func (r *Detector) Run(ctx context.Context) (DetectRes, error) {
-	refHash, refName, err := r.Git.Ref(r.CompareRef)
+	refHash, refName, err := r.Source.Ref(r.CompareRef)
 	if err != nil {
 		return DetectRes{}, fmt.Errorf("failed to get ref: %w", err)
 	}
 
 	res := DetectRes{
+		Reasons:  []ChangeReason{},
+		Triggers: []string{},
+		Mod: DetectModRes{
+			Godebug:      []string{},
+			Tools:        DetectToolsRes{Added: []string{}, Deleted: []string{}},
+			Dependencies: []DetectDependencyRes{},
+		},
 		Git: DetectGitRes{
 			Hash: refHash,
 			Ref:  refName,
@@ -148,23 +526,85 @@ func (r *Detector) Run(ctx context.Context) (DetectRes, error) {
 				Deleted:  DetectFileTypeRes{Go: []string{}, All: []string{}},
 				Updated:  DetectFileTypeRes{Go: []string{}, All: []string{}},
 				Impacted: DetectFileTypeRes{Go: []string{}, All: []string{}},
+				Ignored:  DetectFileTypeRes{Go: []string{}, All: []string{}},
 			},
 		},
 		Stats:       DetectStatsRes{StartedAt: time.Now(), EndedAt: time.Now()},
 		Entrypoints: []DetectEntrypointRes{},
+		Libraries:   []DetectEntrypointRes{},
+		Waves:       [][]string{},
 	}
 
-	diffResult, err := r.Git.Diff(r.CompareRef, r.BaseRef)
+	diffResult, err := r.Source.Diff(r.CompareRef, r.BaseRef)
 	if err != nil {
 		return DetectRes{}, fmt.Errorf("failed to load diff: %w", err)
 	}
 
+	diffResult, ignored := r.withoutIgnored(diffResult)
 	r.populateFilesFromChanges(&res.Git.Files, diffResult)
+	res.Git.Files.Ignored.All = ignored
+	res.Git.Files.Ignored.Go = lo.Filter(ignored, func(file string, _ int) bool { return strings.HasSuffix(file, ".go") })
+
+	// Global triggers mark all entrypoints as changed, so they are also reported as a repository wide reason
+	if res.Triggers, _ = glob.Filter(r.Triggers, diffResult.All()); len(res.Triggers) > 0 {
+		res.Reasons = append(res.Reasons, GlobalTriggerReason)
+	}
 
 	if len(diffResult.All()) == 0 {
-		res.Entrypoints = lo.Map(r.Entrypoints, func(item string, _ int) DetectEntrypointRes {
-			return DetectEntrypointRes{Path: item, Changed: false, Reasons: []ChangeReason{NoGitChangesReason}}
+		targets, err := r.targetsOnRef(ctx, r.CompareRef)
+		if err != nil {
+			return DetectRes{}, fmt.Errorf("failed to get entrypoints: %w", err)
+		}
+
+		results := lo.Map(targets, func(item string, _ int) DetectEntrypointRes {
+			reasons := []ChangeReason{NoGitChangesReason}
+			return DetectEntrypointRes{
+				Path:         item,
+				Changed:      false,
+				Reasons:      reasons,
+				Dependencies: []DetectDependencyRes{},
+				Inputs:       []string{},
+				Ignored:      []string{},
+				Evidence:     r.evidence(item, reasons, explainInfo{}),
+			}
 		})
+		r.populateMetadata(results)
+		res.Entrypoints, res.Libraries = r.splitLibraries(results)
+		res.Stats.end()
+		return res, nil
+	}
+
+	// Every entrypoint is changed anyway, so the base ref is not analysed at all (mod is left empty)
+	if len(res.Triggers) > 0 {
+		targets, err := r.targetsOnRef(ctx, r.CompareRef)
+		if err != nil {
+			return DetectRes{}, fmt.Errorf("failed to get entrypoints: %w", err)
+		}
+
+		results := lo.Map(targets, func(item string, _ int) DetectEntrypointRes {
+			reasons := []ChangeReason{GlobalTriggerReason}
+			return DetectEntrypointRes{
+				Path:         item,
+				Changed:      true,
+				Reasons:      reasons,
+				Dependencies: []DetectDependencyRes{},
+				Inputs:       []string{},
+				Ignored:      []string{},
+				Triggers:     res.Triggers,
+				Evidence:     r.evidence(item, reasons, explainInfo{changes: diffResult.All()}),
+			}
+		})
+
+		results, waves, err := r.dependsOn(results, targets)
+		if err != nil {
+			return DetectRes{}, err
+		}
+
+		r.populateMetadata(results)
+		res.Entrypoints, res.Libraries = r.splitLibraries(results)
+		res.Waves = waves
+		res.Stats.end()
+		res.Changed, res.LibrariesChanged = anyChanged(res.Entrypoints), anyChanged(res.Libraries)
 		return res, nil
 	}
 
@@ -173,18 +613,30 @@ func (r *Detector) Run(ctx context.Context) (DetectRes, error) {
 		return DetectRes{}, fmt.Errorf("failure while getting main tree info: %w", err)
 	}
 
-	diffInfo, err := r.getDiffInfo(ctx, mainInfo, diffResult.All())
+	diffInfo, err := r.getDiffInfo(ctx, mainInfo, diffResult)
 	if err != nil {
 		return DetectRes{}, fmt.Errorf("failure while getting diff info: %w", err)
 	}
 
-	res.Entrypoints = diffInfo.entrypoints
-	res.Stats.EndedAt = time.Now()
-	res.Stats.Duration = res.Stats.EndedAt.Sub(res.Stats.StartedAt) / time.Millisecond
-	res.Changed = lo.SomeBy(res.Entrypoints, func(item DetectEntrypointRes) bool {
+	results, waves, err := r.dependsOn(diffInfo.entrypoints, diffInfo.targets)
+	if err != nil {
+		return DetectRes{}, err
+	}
+
+	r.populateMod(&res, diffInfo.mod)
+	r.populateMetadata(results)
+	res.Entrypoints, res.Libraries = r.splitLibraries(results)
+	res.Waves = waves
+	res.Stats.end()
+	res.Changed, res.LibrariesChanged = anyChanged(res.Entrypoints), anyChanged(res.Libraries)
+	return res, err
+}
+
+// anyChanged returns true if any of the results changed
+func anyChanged(results []DetectEntrypointRes) bool {
+	return lo.SomeBy(results, func(item DetectEntrypointRes) bool {
 		return item.Changed
 	})
-	return res, err
 }
 
 func (r *Detector) populateFilesFromChanges(files *DetectGitChangesRes, changes git.DiffResult) {
@@ -202,20 +654,82 @@ func (r *Detector) populateFilesFromChanges(files *DetectGitChangesRes, changes
 	files.Impacted.All = append(files.Impacted.All, files.Updated.All...)
 }
 
+func (r *Detector) populateMod(res *DetectRes, modDiff mod.Output) {
+	res.Mod.Godebug = append(res.Mod.Godebug, modDiff.Godebug...)
+	res.Mod.Tools.Added = append(res.Mod.Tools.Added, modDiff.Tools.Added...)
+	res.Mod.Tools.Deleted = append(res.Mod.Tools.Deleted, modDiff.Tools.Deleted...)
+	res.Mod.Dependencies = dependenciesRes(modDiff, modDiff.Packages.All())
+
+	// Tools are not linked into entrypoints, but CI might want to re-run code generation
+	if len(modDiff.Tools.All()) > 0 {
+		res.Reasons = append(res.Reasons, ToolsChangedReason)
+	}
+}
+
+// populateMetadata sets the name and attributes configured for each entrypoint
+func (r *Detector) populateMetadata(entrypoints []DetectEntrypointRes) {
+	for i, entry := range entrypoints {
+		for path, metadata := range r.Metadata {
+			if glob.Clean(path) == glob.Clean(entry.Path) {
+				entrypoints[i].Name = metadata.Name
+				entrypoints[i].Attributes = metadata.Attributes
+			}
+		}
+	}
+}
+
+// dependenciesRes returns version details for the given modules, sorted by path
+func dependenciesRes(modDiff mod.Output, modules []string) []DetectDependencyRes {
+	deps := []DetectDependencyRes{}
+	for _, module := range lo.Uniq(modules) {
+		bump, ok := modDiff.Packages.Bumps[module]
+		if !ok {
+			continue
+		}
+		deps = append(deps, DetectDependencyRes{Path: bump.Path, Old: bump.Old, New: bump.New, Bump: bump.Type})
+	}
+
+	slices.SortFunc(deps, func(a, b DetectDependencyRes) int { return strings.Compare(a.Path, b.Path) })
+	return deps
+}
+
 type mainBranchInfo struct {
-	filesByEntrypoint map[string][]string
-	modfile           *modfile.File
+	entrypoints        []string
+	filesByEntrypoint  map[string][]string
+	inputsByEntrypoint map[string][]string
+	modfile            *modfile.File
 }
 
 func (r *Detector) getMainBranchInfo(ctx context.Context) (mainBranchInfo, error) {
+	if r.BaseSnapshot != nil {
+		return r.BaseSnapshot.mainBranchInfo(r.snapshotSettings())
+	}
+	return r.getRefInfo(ctx, r.BaseRef)
+}
+
+// getRefInfo walks the entrypoints on the ref, listing their files and extra inputs
+func (r *Detector) getRefInfo(ctx context.Context, ref string) (mainBranchInfo, error) {
 	info := mainBranchInfo{filesByEntrypoint: map[string][]string{}}
-	err := r.Git.RunOnRef(r.BaseRef, func() error {
-		w, err := walker.New(r.Path, r.Logger.WithGroup("walker:main"))
+	err := r.Source.RunOnRef(ref, func(dir string) error {
+		w, err := r.newWalker(dir, ref, "walker:"+ref)
+		if err != nil {
+			return err
+		}
+
+		_, info.modfile, err = mod.Get(mod.WithModDir(dir))
 		if err != nil {
 			return err
 		}
 
-		_, info.modfile, err = mod.Get(mod.WithModDir(r.Path))
+		entrypoints, err := r.targets(ctx, w)
+		if err != nil {
+			return err
+		}
+
+		// Entrypoints not found in main branch are not walked, and are later reported as added
+		info.entrypoints = lo.Filter(entrypoints, func(entry string, _ int) bool { return w.Exists(entry) })
+
+		info.inputsByEntrypoint, err = r.inputFiles(dir, info.entrypoints, r.refInputs(dir))
 		if err != nil {
 			return err
 		}
@@ -223,24 +737,19 @@ func (r *Detector) getMainBranchInfo(ctx context.Context) (mainBranchInfo, error
 		// Runs each entrypoint walker with go routines: you must test it with `-race` enabled
 		eg, ctx := errgroup.WithContext(ctx)
 		rw := sync.RWMutex{}
-		for _, entry := range r.Entrypoints {
+		for _, entry := range info.entrypoints {
 			entry := entry
 			eg.Go(func() error {
 				// Walks through all packages for this entry
 				listerHook := hook.NewLister()
-				err := w.Walk(ctx, entry, listerHook)
+				if err := w.Walk(ctx, entry, listerHook); err != nil {
+					return err
+				}
 
 				// Write operations to shared memory below
 				rw.Lock()
 				defer rw.Unlock()
-
-				if err != nil {
-					// If the entrypoint doesn't exist in main branch, treat as empty
-					r.Logger.Debug("entrypoint not found in main branch", "entry", entry, "error", err)
-					info.filesByEntrypoint[entry] = []string{}
-				} else {
-					info.filesByEntrypoint[entry] = listerHook.Files()
-				}
+				info.filesByEntrypoint[entry] = relPaths(dir, listerHook.Files())
 				return nil
 			})
 		}
@@ -253,34 +762,91 @@ func (r *Detector) getMainBranchInfo(ctx context.Context) (mainBranchInfo, error
 
 type diffInfo struct {
 	entrypoints []DetectEntrypointRes
+	// targets contains the entrypoints and libraries found in the compare ref
+	targets []string
+	mod     mod.Output
 }
 
-func (r *Detector) getDiffInfo(ctx context.Context, mainInfo mainBranchInfo, changes []string) (diffInfo, error) {
+func (r *Detector) getDiffInfo(ctx context.Context, mainInfo mainBranchInfo, diffResult git.DiffResult) (diffInfo, error) {
 	info := diffInfo{entrypoints: []DetectEntrypointRes{}}
-	err := r.Git.RunOnRef(r.CompareRef, func() error {
-		w, err := walker.New(r.Path, r.Logger.WithGroup("walker:ref"))
+	changes := diffResult.All()
+	err := r.Source.RunOnRef(r.CompareRef, func(dir string) error {
+		w, err := r.newWalker(dir, r.CompareRef, "walker:ref")
 		if err != nil {
 			return err
 		}
 
-		changesByAbsPath := lo.Map(changes, func(change string, _ int) string {
-			// nolint
-			abs, _ := filepath.Abs(filepath.Join(r.Path, change))
-			return abs
-		})
+		_, refMod, err := mod.Get(mod.WithModDir(dir))
+		if err != nil {
+			return err
+		}
 
-		_, refMod, err := mod.Get(mod.WithModDir(r.Path))
+		all, err := r.targets(ctx, w)
 		if err != nil {
 			return err
 		}
 
-		// In case Golang got updated, mark all as changed
+		entrypoints, missing := lo.FilterReject(all, func(entry string, _ int) bool { return w.Exists(entry) })
+		for _, entry := range missing {
+			if !slices.Contains(mainInfo.entrypoints, entry) {
+				return fmt.Errorf("entrypoint %s not found in base nor compare refs", entry)
+			}
+		}
+		info.targets = entrypoints
+
+		inputs := r.refInputs(dir)
+		inputsByEntrypoint, err := r.inputFiles(dir, entrypoints, inputs)
+		if err != nil {
+			return err
+		}
+
+		statuses := entrypointStatuses(mainInfo.entrypoints, entrypoints, diffResult.Renamed)
+		movedFrom := lo.Map(lo.Values(statuses), func(s entrypointStatus, _ int) string { return s.movedFrom })
+
+		// Entrypoints only found in the base ref are reported as removed, without walking them
+		for _, entry := range lo.Without(mainInfo.entrypoints, entrypoints...) {
+			if slices.Contains(movedFrom, entry) {
+				continue
+			}
+			reasons := []ChangeReason{EntrypointRemovedReason}
+			info.entrypoints = append(info.entrypoints, DetectEntrypointRes{
+				Path:         entry,
+				Status:       EntrypointRemoved,
+				Changed:      true,
+				Reasons:      reasons,
+				Dependencies: []DetectDependencyRes{},
+				Inputs:       []string{},
+				Ignored:      []string{},
+				Evidence:     r.evidence(entry, reasons, explainInfo{}),
+			})
+		}
+
+		// In case Golang got updated (according to the policies) or a global trigger changed, mark all as changed
 		modDiff := mod.Diff(mainInfo.modfile, refMod)
-		if modDiff.Type == mod.ChangeGolang {
-			info = diffInfo{
-				entrypoints: lo.Map(r.Entrypoints, func(item string, _ int) DetectEntrypointRes {
-					return DetectEntrypointRes{Path: item, Changed: true, Reasons: []ChangeReason{GoVersionChangedReason}}
-				}),
+		info.mod = modDiff
+		if globalReasons := r.globalReasons(modDiff); len(globalReasons) > 0 {
+			for _, entry := range entrypoints {
+				ignored, entryChanges := glob.Filter(globsFor(r.EntrypointIgnore, entry), changes)
+				reasons := slices.Concat(statuses[entry].reasons(), globalReasons, r.godebugReasons(entry, modDiff))
+				reasons = append(reasons, r.inputsReasons(inputs, entry, entryChanges)...)
+				reasons = append(reasons, inputFilesReasons(mainInfo.inputsByEntrypoint[entry], inputsByEntrypoint[entry])...)
+				info.entrypoints = append(info.entrypoints, DetectEntrypointRes{
+					Path:         entry,
+					Status:       statuses[entry].status,
+					MovedFrom:    statuses[entry].movedFrom,
+					Changed:      true,
+					Reasons:      reasons,
+					Dependencies: []DetectDependencyRes{},
+					Inputs:       inputsByEntrypoint[entry],
+					Ignored:      ignored,
+					Evidence: r.evidence(entry, reasons, explainInfo{
+						changes:       changes,
+						entryChanges:  entryChanges,
+						inputs:        inputs,
+						baseInputs:    mainInfo.inputsByEntrypoint[entry],
+						compareInputs: inputsByEntrypoint[entry],
+					}),
+				})
 			}
 			return nil
 		}
@@ -288,15 +854,22 @@ func (r *Detector) getDiffInfo(ctx context.Context, mainInfo mainBranchInfo, cha
 		// Runs each entrypoint walker with go routines: you must test it with `-race` enabled
 		eg, ctx := errgroup.WithContext(ctx)
 		rw := sync.RWMutex{}
-		for _, entry := range r.Entrypoints {
+		for _, entry := range entrypoints {
 			entry := entry
 			eg.Go(func() error {
 				// Walks through all packages for this entry
-				reasons := []ChangeReason{}
-				changesHook := hook.NewChangeDetector(changesByAbsPath)
+				reasons := statuses[entry].reasons()
+				entryIgnore := globsFor(r.EntrypointIgnore, entry)
+				ignored, entryChanges := glob.Filter(entryIgnore, changes)
+				changesHook := hook.NewChangeDetector(absPaths(dir, entryChanges))
 				listerHook := hook.NewLister()
 				modHook := hook.NewModDetector(modDiff.Packages.All())
-				if err := w.Walk(ctx, entry, changesHook, listerHook, modHook); err != nil {
+				chainsHook := hook.NewChainFinder()
+				hooks := []walker.Hook{changesHook, listerHook, modHook}
+				if r.Explain {
+					hooks = append(hooks, chainsHook)
+				}
+				if err := w.Walk(ctx, entry, hooks...); err != nil {
 					return err
 				}
 
@@ -304,22 +877,46 @@ func (r *Detector) getDiffInfo(ctx context.Context, mainInfo mainBranchInfo, cha
 				if changesHook.Found() {
 					reasons = append(reasons, ChangedFilesReason)
 				}
-				if !lo.ElementsMatch(mainInfo.filesByEntrypoint[entry], listerHook.Files()) {
+				baseFiles := r.withoutIgnoredFiles(mainInfo.filesByEntrypoint[entry], entryIgnore)
+				compareFiles := r.withoutIgnoredFiles(relPaths(dir, listerHook.Files()), entryIgnore)
+				if !lo.ElementsMatch(baseFiles, compareFiles) {
 					reasons = append(reasons, CreatedDeletedFilesReasons)
 				}
 				if modHook.Found() {
 					reasons = append(reasons, DependenciesChangedReason)
 				}
+				reasons = append(reasons, r.godebugReasons(entry, modDiff)...)
+				reasons = append(reasons, r.inputsReasons(inputs, entry, entryChanges)...)
+				reasons = append(reasons, inputFilesReasons(mainInfo.inputsByEntrypoint[entry], inputsByEntrypoint[entry])...)
 
 				// Write operations to shared memory below
 				rw.Lock()
 				defer rw.Unlock()
 				changed := len(reasons) > 0
 				if changed || r.ShowUnchanged {
+					dependencies := dependenciesRes(modDiff, modHook.Modules())
 					info.entrypoints = append(info.entrypoints, DetectEntrypointRes{
-						Path:    entry,
-						Changed: changed,
-						Reasons: reasons,
+						Path:         entry,
+						Status:       statuses[entry].status,
+						MovedFrom:    statuses[entry].movedFrom,
+						Changed:      changed,
+						Reasons:      reasons,
+						Dependencies: dependencies,
+						Inputs:       inputsByEntrypoint[entry],
+						Ignored:      ignored,
+						Evidence: r.evidence(entry, reasons, explainInfo{
+							root:          dir,
+							changes:       changes,
+							entryChanges:  entryChanges,
+							matches:       changesHook.Matches(),
+							chains:        chainsHook,
+							baseFiles:     baseFiles,
+							compareFiles:  compareFiles,
+							modules:       dependencies,
+							inputs:        inputs,
+							baseInputs:    mainInfo.inputsByEntrypoint[entry],
+							compareInputs: inputsByEntrypoint[entry],
+						}),
 					})
 				}
 				return nil
@@ -331,3 +928,306 @@ func (r *Detector) getDiffInfo(ctx context.Context, mainInfo mainBranchInfo, cha
 
 	return info, err
 }
+
+// explainInfo contains what the reasons of an entrypoint were based on
+type explainInfo struct {
+	root string
+	// changes and entryChanges contain the repository relative changes, before and after the entrypoint ignores
+	changes      []string
+	entryChanges []string
+	// matches contains the package path of each changed file found while walking, by absolute path
+	matches map[string]string
+	chains  *hook.ChainFinder
+	// baseFiles and compareFiles contain the repository relative paths listed while walking each ref
+	baseFiles    []string
+	compareFiles []string
+	modules      []DetectDependencyRes
+	// inputs contains the extra inputs globs, by entrypoint path, of the compared side
+	inputs        map[string][]string
+	baseInputs    []string
+	compareInputs []string
+	// dependsOn contains the changed entrypoints the entrypoint depends on
+	dependsOn []string
+}
+
+// evidence returns what caused each reason, in the same order, or nil if explain mode is disabled
+func (r *Detector) evidence(entry string, reasons []ChangeReason, info explainInfo) []DetectEvidenceRes {
+	if !r.Explain {
+		return nil
+	}
+
+	evidence := make([]DetectEvidenceRes, 0, len(reasons))
+	for _, reason := range reasons {
+		item := DetectEvidenceRes{Reason: reason}
+		switch reason {
+		case ChangedFilesReason:
+			item.Files = changedFilesEvidence(info.root, info.matches, info.chains)
+		case CreatedDeletedFilesReasons:
+			item.Created = lo.Without(info.compareFiles, info.baseFiles...)
+			item.Deleted = lo.Without(info.baseFiles, info.compareFiles...)
+		case DependenciesChangedReason:
+			item.Modules = info.modules
+		case GoVersionChangedReason, GoToolchainChangedReason, GodebugChangedReason:
+			item.Files = []DetectFileEvidenceRes{{Path: "go.mod"}}
+		case GlobalTriggerReason:
+			triggers, _ := glob.Filter(r.Triggers, info.changes)
+			item.Files = filesEvidence(triggers)
+		case ExtraInputsChangedReason:
+			inputs, _ := glob.Filter(globsFor(info.inputs, entry), info.entryChanges)
+			item.Files = filesEvidence(inputs)
+		case ExtraInputsCreatedDeletedReason:
+			item.Created = lo.Without(info.compareInputs, info.baseInputs...)
+			item.Deleted = lo.Without(info.baseInputs, info.compareInputs...)
+		case DependsOnChangedReason:
+			item.Entrypoints = info.dependsOn
+		}
+		evidence = append(evidence, item)
+	}
+	return evidence
+}
+
+// changedFilesEvidence returns the changed files found while walking, sorted by path
+func changedFilesEvidence(root string, matches map[string]string, chains *hook.ChainFinder) []DetectFileEvidenceRes {
+	files := make([]DetectFileEvidenceRes, 0, len(matches))
+	for file, pkg := range matches {
+		files = append(files, DetectFileEvidenceRes{
+			Path:    relPaths(root, []string{file})[0],
+			Package: pkg,
+			Chain:   chains.Chain(pkg),
+		})
+	}
+	slices.SortFunc(files, func(a, b DetectFileEvidenceRes) int { return strings.Compare(a.Path, b.Path) })
+	return files
+}
+
+// filesEvidence maps repository relative files which are not tied to any package
+func filesEvidence(files []string) []DetectFileEvidenceRes {
+	return lo.Map(files, func(file string, _ int) DetectFileEvidenceRes { return DetectFileEvidenceRes{Path: file} })
+}
+
+// globalReasons returns the reasons marking all entrypoints as changed: go directive and toolchain changes.
+// Global triggers are handled before any ref is walked.
+func (r *Detector) globalReasons(modDiff mod.Output) []ChangeReason {
+	reasons := []ChangeReason{}
+	if r.GoVersionPolicy.Triggers(modDiff.Golang) {
+		reasons = append(reasons, GoVersionChangedReason)
+	}
+	if r.ToolchainPolicy.Triggers(modDiff.Toolchain) {
+		reasons = append(reasons, GoToolchainChangedReason)
+	}
+	return reasons
+}
+
+// godebugReasons returns the godebug reason if it changed and the entrypoint is within its scope
+func (r *Detector) godebugReasons(entry string, modDiff mod.Output) []ChangeReason {
+	if len(modDiff.Godebug) == 0 {
+		return []ChangeReason{}
+	}
+	if len(r.GodebugScope) > 0 && !glob.MatchAny(r.GodebugScope, entry) {
+		return []ChangeReason{}
+	}
+	return []ChangeReason{GodebugChangedReason}
+}
+
+type entrypointStatus struct {
+	status    EntrypointStatus
+	movedFrom string
+}
+
+func (s entrypointStatus) reasons() []ChangeReason {
+	switch s.status {
+	case EntrypointAdded:
+		return []ChangeReason{EntrypointAddedReason}
+	case EntrypointMoved:
+		return []ChangeReason{EntrypointMovedReason}
+	default:
+		return []ChangeReason{}
+	}
+}
+
+// entrypointStatuses returns the status of entrypoints only found in the compare ref. They are considered
+// moved if their Go files were renamed from a directory which is not an entrypoint in the compare ref.
+func entrypointStatuses(baseEntrypoints, compareEntrypoints []string, renamed map[string]string) map[string]entrypointStatus {
+	statuses := map[string]entrypointStatus{}
+	for _, entry := range lo.Without(compareEntrypoints, baseEntrypoints...) {
+		statuses[entry] = entrypointStatus{status: EntrypointAdded}
+		for to, from := range renamed {
+			if path.Dir(to) != glob.Clean(entry) || !strings.HasSuffix(to, ".go") {
+				continue
+			}
+
+			source := path.Dir(from)
+			if lo.ContainsBy(compareEntrypoints, func(e string) bool { return glob.Clean(e) == source }) {
+				continue
+			}
+
+			// Keep the path as configured, so it can be matched with the removed entrypoint
+			if base, ok := lo.Find(baseEntrypoints, func(e string) bool { return glob.Clean(e) == source }); ok {
+				source = base
+			}
+			statuses[entry] = entrypointStatus{status: EntrypointMoved, movedFrom: source}
+			break
+		}
+	}
+	return statuses
+}
+
+// newWalker returns a walker for the ref tree in dir, caching its packages if the source identifies the tree
+func (r *Detector) newWalker(dir, ref, group string, opts ...walker.WithOpt) (*walker.Walker, error) {
+	if hasher, ok := r.Source.(treeHasher); ok && r.Cache != nil {
+		tree, err := hasher.TreeHash(ref)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tree: %w", err)
+		}
+		opts = append(opts, walker.WithCache(r.Cache, tree))
+	}
+	return walker.New(dir, r.Logger.WithGroup(group), opts...)
+}
+
+// targetsOnRef returns the entrypoints and libraries for the ref, only checking it out if discovery is enabled
+func (r *Detector) targetsOnRef(ctx context.Context, ref string) ([]string, error) {
+	if !r.Discover {
+		return slices.Concat(r.Entrypoints, r.Libraries), nil
+	}
+
+	var targets []string
+	err := r.Source.RunOnRef(ref, func(dir string) error {
+		w, err := r.newWalker(dir, ref, "walker:"+ref)
+		if err != nil {
+			return err
+		}
+		targets, err = r.targets(ctx, w)
+		return err
+	})
+	return targets, err
+}
+
+// splitLibraries splits the results into entrypoints and libraries
+func (r *Detector) splitLibraries(results []DetectEntrypointRes) ([]DetectEntrypointRes, []DetectEntrypointRes) {
+	libraries, entrypoints := lo.FilterReject(results, func(item DetectEntrypointRes, _ int) bool {
+		return lo.ContainsBy(r.Libraries, func(library string) bool { return glob.Clean(library) == glob.Clean(item.Path) })
+	})
+	return entrypoints, libraries
+}
+
+// inputsReasons returns the reason triggered by changes to the entrypoint's extra inputs
+func (r *Detector) inputsReasons(inputs map[string][]string, entry string, changes []string) []ChangeReason {
+	reasons := []ChangeReason{}
+	if globs := globsFor(inputs, entry); lo.SomeBy(changes, func(change string) bool { return glob.MatchAny(globs, change) }) {
+		reasons = append(reasons, ExtraInputsChangedReason)
+	}
+	return reasons
+}
+
+// inputFilesReasons returns the created/deleted reason if the files matching the extra inputs differ between refs
+func inputFilesReasons(base, compare []string) []ChangeReason {
+	if lo.ElementsMatch(base, compare) {
+		return []ChangeReason{}
+	}
+	return []ChangeReason{ExtraInputsCreatedDeletedReason}
+}
+
+// inputFiles returns the files matching the extra inputs of each entrypoint, in the checked out ref
+func (r *Detector) inputFiles(dir string, entrypoints []string, inputs map[string][]string) (map[string][]string, error) {
+	files := map[string][]string{}
+	for _, entry := range entrypoints {
+		files[entry] = []string{}
+	}
+
+	// The tree is only walked once, with the globs of all entrypoints
+	patterns := lo.FlatMap(entrypoints, func(entry string, _ int) []string { return globsFor(inputs, entry) })
+	matched, err := glob.Glob(dir, patterns)
+	if err != nil {
+		return nil, fmt.Errorf("failed to match extra inputs: %w", err)
+	}
+
+	for _, entry := range entrypoints {
+		files[entry], _ = glob.Filter(globsFor(inputs, entry), matched)
+	}
+	return files, nil
+}
+
+// refInputs returns the extra inputs from the config file at the ref checked out in dir. The current
+// ones are used if no config file is set or it is not valid in the ref.
+func (r *Detector) refInputs(dir string) map[string][]string {
+	if r.ConfigFile == "" {
+		return r.Inputs
+	}
+
+	// The config file is within the repository, but the base ref might be checked out elsewhere
+	path := r.ConfigFile
+	if rel, err := filepath.Rel(r.Path, r.ConfigFile); err == nil && !strings.HasPrefix(rel, "..") {
+		path = filepath.Join(dir, rel)
+	}
+
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return map[string][]string{}
+	}
+
+	cfg, err := ReadConfig(path)
+	if err != nil {
+		r.Logger.Warn("ignoring config file from ref", "dir", dir, "error", err)
+		return r.Inputs
+	}
+	return cfg.globsByEntrypoint(func(e ConfigEntrypoint) []string { return e.Inputs })
+}
+
+// globsFor returns the globs configured for the entrypoint, which might be configured as `./cmd/app` or `cmd/app`
+func globsFor(globsByEntrypoint map[string][]string, entry string) []string {
+	for path, globs := range globsByEntrypoint {
+		if glob.Clean(path) == glob.Clean(entry) {
+			return globs
+		}
+	}
+	return nil
+}
+
+// withoutIgnored removes files matching the ignore globs from the diff, returning them separately
+func (r *Detector) withoutIgnored(changes git.DiffResult) (git.DiffResult, []string) {
+	ignored := []string{}
+	if len(r.Ignore) == 0 {
+		return changes, ignored
+	}
+
+	for _, files := range []*[]string{&changes.Created, &changes.Updated, &changes.Deleted} {
+		var matched []string
+		matched, *files = glob.Filter(r.Ignore, *files)
+		ignored = append(ignored, matched...)
+	}
+	slices.Sort(ignored)
+	return changes, ignored
+}
+
+// withoutIgnoredFiles removes repository relative paths matching the global or the given ignore globs, so
+// ignored files created or deleted within the walked packages are not considered
+func (r *Detector) withoutIgnoredFiles(files []string, ignore []string) []string {
+	_, kept := glob.Filter(slices.Concat(r.Ignore, ignore), files)
+	return kept
+}
+
+// relPaths maps absolute paths to sorted paths relative to root. Paths outside root are kept as is.
+func relPaths(root string, files []string) []string {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return files
+	}
+
+	rel := lo.Map(files, func(file string, _ int) string {
+		path, err := filepath.Rel(root, file)
+		if err != nil || strings.HasPrefix(path, "..") {
+			return file
+		}
+		return filepath.ToSlash(path)
+	})
+	slices.Sort(rel)
+	return rel
+}
+
+// absPaths maps the repository relative changes to absolute paths within root, as used by the walked packages
+func absPaths(root string, changes []string) []string {
+	return lo.Map(changes, func(change string, _ int) string {
+		// nolint
+		abs, _ := filepath.Abs(filepath.Join(root, change))
+		return abs
+	})
+}
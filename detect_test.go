@@ -3,14 +3,21 @@ package monogo_test
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/brunoluiz/monogo"
+	"github.com/brunoluiz/monogo/dirs"
+	"github.com/brunoluiz/monogo/diskcache"
 	xgit "github.com/brunoluiz/monogo/git"
+	"github.com/brunoluiz/monogo/mod"
+	"github.com/brunoluiz/monogo/patch"
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
@@ -26,6 +33,111 @@ func findEntrypoint(entrypoints []monogo.DetectEntrypointRes, path string) *mono
 	return nil
 }
 
+// setupTestRepo copies the test project into a git repository with a main branch and
+// checks out a test branch from it. It returns the repository folder, worktree and test branch.
+func setupTestRepo(t *testing.T) (string, *git.Worktree, plumbing.ReferenceName) {
+	t.Helper()
+
+	// setup folder
+	tmpDir := filepath.Join("./tmp", t.Name())
+	require.NoError(t, os.RemoveAll(tmpDir))
+	require.NoError(t, os.MkdirAll(tmpDir, 0o755))
+	require.NoError(t, os.CopyFS(tmpDir, os.DirFS("./testdata/test-project")))
+
+	// setup git
+	repo, err := git.PlainInitWithOptions(tmpDir, &git.PlainInitOptions{
+		InitOptions: git.InitOptions{DefaultBranch: plumbing.NewBranchReferenceName("main")},
+	})
+	require.NoError(t, err)
+	w, err := repo.Worktree()
+	require.NoError(t, err)
+
+	// initial commit
+	_, err = w.Add(".")
+	require.NoError(t, err)
+	_, err = w.Commit("initial commit", &git.CommitOptions{
+		Author: &object.Signature{Name: "Test User", Email: "test@example.com", When: time.Now()},
+	})
+	require.NoError(t, err)
+
+	// checkout to a new branch based on main
+	ref, err := repo.Head()
+	require.NoError(t, err)
+	b := plumbing.NewBranchReferenceName("test-branch")
+	require.NoError(t, w.Checkout(&git.CheckoutOptions{
+		Create: true,
+		Branch: b,
+		Hash:   ref.Hash(),
+	}))
+
+	return tmpDir, w, b
+}
+
+// commitFile writes the content into the target file and commits it
+func commitFile(t *testing.T, w *git.Worktree, targetFile, content string) {
+	t.Helper()
+
+	worktreeTargetPath := filepath.Join(w.Filesystem.Root(), targetFile)
+	require.NoError(t, os.MkdirAll(filepath.Dir(worktreeTargetPath), 0o755))
+	require.NoError(t, os.WriteFile(worktreeTargetPath, []byte(content), 0o600))
+
+	_, err := w.Add(targetFile)
+	require.NoError(t, err)
+	_, err = w.Commit("write "+targetFile, &git.CommitOptions{
+		Author: &object.Signature{Name: "Test User", Email: "test@example.com", When: time.Now()},
+	})
+	require.NoError(t, err)
+}
+
+// commitRemove removes the target file and commits it
+func commitRemove(t *testing.T, w *git.Worktree, targetFile string) {
+	t.Helper()
+
+	_, err := w.Remove(targetFile)
+	require.NoError(t, err)
+	_, err = w.Commit("remove "+targetFile, &git.CommitOptions{
+		Author: &object.Signature{Name: "Test User", Email: "test@example.com", When: time.Now()},
+	})
+	require.NoError(t, err)
+}
+
+// commitRename moves the target file and commits it
+func commitRename(t *testing.T, w *git.Worktree, from, to string) {
+	t.Helper()
+
+	root := w.Filesystem.Root()
+	require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(root, to)), 0o755))
+	require.NoError(t, os.Rename(filepath.Join(root, from), filepath.Join(root, to)))
+
+	_, err := w.Remove(from)
+	require.NoError(t, err)
+	_, err = w.Add(to)
+	require.NoError(t, err)
+	_, err = w.Commit("move "+from+" to "+to, &git.CommitOptions{
+		Author: &object.Signature{Name: "Test User", Email: "test@example.com", When: time.Now()},
+	})
+	require.NoError(t, err)
+}
+
+// commitFileReplace replaces the first occurrence of from by to in the target file and commits it
+func commitFileReplace(t *testing.T, w *git.Worktree, targetFile, from, to string) {
+	t.Helper()
+
+	worktreeTargetPath := filepath.Join(w.Filesystem.Root(), targetFile)
+	data, err := os.ReadFile(worktreeTargetPath)
+	require.NoError(t, err)
+
+	newData := bytes.Replace(data, []byte(from), []byte(to), 1)
+	require.NoError(t, os.WriteFile(worktreeTargetPath, newData, 0o600))
+
+	_, err = w.Add(targetFile)
+	require.NoError(t, err)
+	_, err = w.Commit("replace "+from+" in "+targetFile, &git.CommitOptions{
+		Author: &object.Signature{Name: "Test User", Email: "test@example.com", When: time.Now()},
+	})
+	require.NoError(t, err)
+}
+
 // nolint: funlen
 func TestDetector_Run(t *testing.T) {
 	t.Parallel()
@@ -38,8 +150,21 @@ func TestDetector_Run(t *testing.T) {
 	}
 
 	type fields struct {
-		entrypoints   []string
-		showUnchanged bool
+		entrypoints      []string
+		showUnchanged    bool
+		goVersionPolicy  mod.Policy
+		toolchainPolicy  mod.Policy
+		godebugScope     []string
+		ignore           []string
+		entrypointIgnore map[string][]string
+		triggers         []string
+		inputs           map[string][]string
+		configFile       bool
+		discover         bool
+		discoverGlobs    []string
+		explain          bool
+		metadata         map[string]monogo.EntrypointMetadata
+		libraries        []string
 	}
 
 	tests := []struct {
@@ -98,6 +223,269 @@ func TestDetector_Run(t *testing.T) {
 				require.NotContains(t, res.Git.Files.Updated.Go, "go.mod")
 			},
 		},
+		{
+			name: "should detect go toolchain upgrade",
+			fields: fields{
+				entrypoints:   []string{"cmd/app1", "cmd/app2", "cmd/app3"},
+				showUnchanged: false,
+			},
+			prepare: func(t *testing.T, w *git.Worktree) {
+				commitFileReplace(t, w, "go.mod", "go 1.22", "go 1.22\n\ntoolchain go1.22.5")
+			},
+			assert: func(t *testing.T, res monogo.DetectRes) {
+				require.True(t, findEntrypoint(res.Entrypoints, "cmd/app1").Changed)
+				require.Equal(t, []monogo.ChangeReason{monogo.GoToolchainChangedReason}, findEntrypoint(res.Entrypoints, "cmd/app1").Reasons)
+				require.True(t, findEntrypoint(res.Entrypoints, "cmd/app2").Changed)
+				require.True(t, findEntrypoint(res.Entrypoints, "cmd/app3").Changed)
+			},
+		},
+		{
+			name: "should ignore go patch upgrade when policy is patch-ignored",
+			fields: fields{
+				entrypoints:     []string{"cmd/app1", "cmd/app2", "cmd/app3"},
+				showUnchanged:   false,
+				goVersionPolicy: mod.PolicyPatchIgnored,
+				toolchainPolicy: mod.PolicyPatchIgnored,
+			},
+			prepare: func(t *testing.T, w *git.Worktree) {
+				commitFileReplace(t, w, "go.mod", "go 1.22", "go 1.22.1")
+			},
+			assert: func(t *testing.T, res monogo.DetectRes) {
+				require.Empty(t, res.Entrypoints)
+				require.Contains(t, res.Git.Files.Updated.All, "go.mod")
+			},
+		},
+		{
+			name: "should detect go minor upgrade when policy is minor-only",
+			fields: fields{
+				entrypoints:     []string{"cmd/app1", "cmd/app2", "cmd/app3"},
+				showUnchanged:   false,
+				goVersionPolicy: mod.PolicyMinorOnly,
+				toolchainPolicy: mod.PolicyNone,
+			},
+			prepare: func(t *testing.T, w *git.Worktree) {
+				commitFileReplace(t, w, "go.mod", "go 1.22", "go 1.23")
+			},
+			assert: func(t *testing.T, res monogo.DetectRes) {
+				require.True(t, findEntrypoint(res.Entrypoints, "cmd/app1").Changed)
+				require.Equal(t, []monogo.ChangeReason{monogo.GoVersionChangedReason}, findEntrypoint(res.Entrypoints, "cmd/app1").Reasons)
+			},
+		},
+		{
+			name: "should ignore go version bump when only the go version policy is none",
+			fields: fields{
+				entrypoints:     []string{"cmd/app1", "cmd/app2", "cmd/app3"},
+				showUnchanged:   false,
+				goVersionPolicy: mod.PolicyNone,
+			},
+			prepare: func(t *testing.T, w *git.Worktree) {
+				commitFileReplace(t, w, "go.mod", "go 1.22", "go 1.23")
+			},
+			assert: func(t *testing.T, res monogo.DetectRes) {
+				require.Empty(t, res.Entrypoints)
+			},
+		},
+		{
+			name: "should detect godebug changes within scope",
+			fields: fields{
+				entrypoints:   []string{"cmd/app1", "cmd/app2", "cmd/app3"},
+				showUnchanged: false,
+				godebugScope:  []string{"cmd/app1"},
+			},
+			prepare: func(t *testing.T, w *git.Worktree) {
+				commitFileReplace(t, w, "go.mod", "go 1.22", "go 1.22\n\ngodebug panicnil=1")
+			},
+			assert: func(t *testing.T, res monogo.DetectRes) {
+				require.True(t, findEntrypoint(res.Entrypoints, "cmd/app1").Changed)
+				require.Equal(t, []monogo.ChangeReason{monogo.GodebugChangedReason}, findEntrypoint(res.Entrypoints, "cmd/app1").Reasons)
+				require.Nil(t, findEntrypoint(res.Entrypoints, "cmd/app2"))
+				require.Nil(t, findEntrypoint(res.Entrypoints, "cmd/app3"))
+				require.Equal(t, []string{"panicnil"}, res.Mod.Godebug)
+			},
+		},
+		{
+			name: "should report tool changes as a repository reason",
+			fields: fields{
+				entrypoints:   []string{"cmd/app1", "cmd/app2", "cmd/app3"},
+				showUnchanged: false,
+			},
+			prepare: func(t *testing.T, w *git.Worktree) {
+				commitFileReplace(t, w, "go.mod", "go 1.22", "go 1.22\n\ntool test-project/cmd/app2")
+			},
+			assert: func(t *testing.T, res monogo.DetectRes) {
+				require.Empty(t, res.Entrypoints)
+				require.Equal(t, []monogo.ChangeReason{monogo.ToolsChangedReason}, res.Reasons)
+				require.Equal(t, []string{"test-project/cmd/app2"}, res.Mod.Tools.Added)
+			},
+		},
+		{
+			name: "should not detect changes in ignored files",
+			fields: fields{
+				entrypoints:   []string{"cmd/app1", "cmd/app2", "cmd/app3"},
+				showUnchanged: false,
+				ignore:        []string{"**/*.md"},
+			},
+			prepare: func(t *testing.T, w *git.Worktree) {
+				commitFile(t, w, filepath.Join("pkg", "shared", "README.md"), "# shared")
+			},
+			assert: func(t *testing.T, res monogo.DetectRes) {
+				require.False(t, res.Changed)
+				require.Empty(t, res.Git.Files.Created.All)
+				require.Equal(t, []string{"pkg/shared/README.md"}, res.Git.Files.Ignored.All)
+				require.Empty(t, res.Git.Files.Ignored.Go)
+				require.Equal(t, []monogo.ChangeReason{monogo.NoGitChangesReason}, findEntrypoint(res.Entrypoints, "cmd/app1").Reasons)
+			},
+		},
+		{
+			name: "should not detect changes in files ignored by the entrypoint",
+			fields: fields{
+				entrypoints:      []string{"cmd/app1", "cmd/app2", "cmd/app3"},
+				showUnchanged:    true,
+				entrypointIgnore: map[string][]string{"cmd/app1": {"**/mock_*.go"}},
+			},
+			prepare: func(t *testing.T, w *git.Worktree) {
+				commitFile(t, w, filepath.Join("pkg", "pkgA", "mock_gen.go"), "package pkgA\n\nfunc Mock() {}\n")
+			},
+			assert: func(t *testing.T, res monogo.DetectRes) {
+				require.False(t, findEntrypoint(res.Entrypoints, "cmd/app1").Changed)
+				require.Equal(t, []string{"pkg/pkgA/mock_gen.go"}, findEntrypoint(res.Entrypoints, "cmd/app1").Ignored)
+				require.True(t, findEntrypoint(res.Entrypoints, "cmd/app3").Changed)
+				require.Contains(t, findEntrypoint(res.Entrypoints, "cmd/app3").Reasons, monogo.CreatedDeletedFilesReasons)
+				require.Empty(t, findEntrypoint(res.Entrypoints, "cmd/app3").Ignored)
+				require.Empty(t, res.Git.Files.Ignored.All)
+			},
+		},
+		{
+			name: "should mark all entrypoints as changed on global triggers",
+			fields: fields{
+				entrypoints:   []string{"cmd/app1", "cmd/app2", "cmd/app3"},
+				showUnchanged: false,
+				triggers:      []string{"Makefile", ".github/workflows/*.yaml"},
+			},
+			prepare: func(t *testing.T, w *git.Worktree) {
+				commitFile(t, w, "Makefile", "build:\n\tgo build ./...\n")
+			},
+			assert: func(t *testing.T, res monogo.DetectRes) {
+				require.Len(t, res.Entrypoints, 3)
+				for _, entry := range res.Entrypoints {
+					require.True(t, entry.Changed)
+					require.Equal(t, []monogo.ChangeReason{monogo.GlobalTriggerReason}, entry.Reasons)
+					require.Equal(t, []string{"Makefile"}, entry.Triggers)
+				}
+				require.Equal(t, []string{"Makefile"}, res.Triggers)
+				require.Equal(t, []monogo.ChangeReason{monogo.GlobalTriggerReason}, res.Reasons)
+			},
+		},
+		{
+			name: "should detect extra input changes",
+			fields: fields{
+				entrypoints:   []string{"cmd/app1", "cmd/app2", "cmd/app3"},
+				showUnchanged: false,
+				inputs:        map[string][]string{"cmd/app2": {"cmd/app2/Dockerfile", "config/app2/*.yaml"}},
+			},
+			prepare: func(t *testing.T, w *git.Worktree) {
+				commitFile(t, w, filepath.Join("cmd", "app2", "Dockerfile"), "FROM scratch\n")
+			},
+			assert: func(t *testing.T, res monogo.DetectRes) {
+				require.True(t, findEntrypoint(res.Entrypoints, "cmd/app2").Changed)
+				require.Equal(t, []monogo.ChangeReason{
+					monogo.ExtraInputsChangedReason,
+					monogo.ExtraInputsCreatedDeletedReason,
+				}, findEntrypoint(res.Entrypoints, "cmd/app2").Reasons)
+				require.Equal(t, []string{"cmd/app2/Dockerfile"}, findEntrypoint(res.Entrypoints, "cmd/app2").Inputs)
+				require.Nil(t, findEntrypoint(res.Entrypoints, "cmd/app1"))
+				require.Nil(t, findEntrypoint(res.Entrypoints, "cmd/app3"))
+			},
+		},
+		{
+			name: "should detect extra input glob changes from the base config",
+			fields: fields{
+				entrypoints:   []string{"cmd/app1", "cmd/app2", "cmd/app3"},
+				showUnchanged: false,
+				inputs:        map[string][]string{"cmd/app1": {"go.sum"}},
+				configFile:    true,
+			},
+			prepare: func(t *testing.T, w *git.Worktree) {
+				commitFile(t, w, monogo.DefaultConfigFile, "version: 1\nentrypoints:\n  - path: cmd/app1\n    inputs: [go.sum]\n")
+			},
+			assert: func(t *testing.T, res monogo.DetectRes) {
+				require.True(t, findEntrypoint(res.Entrypoints, "cmd/app1").Changed)
+				require.Equal(t, []monogo.ChangeReason{monogo.ExtraInputsCreatedDeletedReason}, findEntrypoint(res.Entrypoints, "cmd/app1").Reasons)
+				require.Equal(t, []string{"go.sum"}, findEntrypoint(res.Entrypoints, "cmd/app1").Inputs)
+				require.Nil(t, findEntrypoint(res.Entrypoints, "cmd/app2"))
+			},
+		},
+		{
+			name: "should read extra inputs from the compare config",
+			fields: fields{
+				entrypoints:   []string{"cmd/app1", "cmd/app2", "cmd/app3"},
+				showUnchanged: false,
+				inputs:        map[string][]string{"cmd/app1": {"go.sum"}},
+				configFile:    true,
+			},
+			prepare: func(t *testing.T, w *git.Worktree) {
+				commitFile(t, w, monogo.DefaultConfigFile, "version: 1\nentrypoints:\n  - path: cmd/app2\n    inputs: [go.sum]\n")
+			},
+			assert: func(t *testing.T, res monogo.DetectRes) {
+				require.Nil(t, findEntrypoint(res.Entrypoints, "cmd/app1"))
+				require.Equal(t, []monogo.ChangeReason{monogo.ExtraInputsCreatedDeletedReason}, findEntrypoint(res.Entrypoints, "cmd/app2").Reasons)
+				require.Equal(t, []string{"go.sum"}, findEntrypoint(res.Entrypoints, "cmd/app2").Inputs)
+			},
+		},
+		{
+			name: "should discover entrypoints added and removed between refs",
+			fields: fields{
+				showUnchanged: true,
+				discover:      true,
+				discoverGlobs: []string{"cmd/*", "!cmd/app3"},
+			},
+			prepare: func(t *testing.T, w *git.Worktree) {
+				commitFile(t, w, filepath.Join("cmd", "app4", "main.go"), "package main\n\nfunc main() {}\n")
+				commitRemove(t, w, filepath.Join("cmd", "app2", "main.go"))
+			},
+			assert: func(t *testing.T, res monogo.DetectRes) {
+				require.Len(t, res.Entrypoints, 3)
+				require.False(t, findEntrypoint(res.Entrypoints, "cmd/app1").Changed)
+				require.Equal(t, []monogo.ChangeReason{monogo.EntrypointRemovedReason}, findEntrypoint(res.Entrypoints, "cmd/app2").Reasons)
+				require.Equal(t, monogo.EntrypointRemoved, findEntrypoint(res.Entrypoints, "cmd/app2").Status)
+				require.True(t, findEntrypoint(res.Entrypoints, "cmd/app4").Changed)
+				require.Equal(t, monogo.EntrypointAddedReason, findEntrypoint(res.Entrypoints, "cmd/app4").Reasons[0])
+				require.Nil(t, findEntrypoint(res.Entrypoints, "cmd/app3"))
+			},
+		},
+		{
+			name: "should report removed entrypoints instead of failing",
+			fields: fields{
+				entrypoints:   []string{"cmd/app1", "cmd/app2"},
+				showUnchanged: false,
+			},
+			prepare: func(t *testing.T, w *git.Worktree) {
+				commitRemove(t, w, filepath.Join("cmd", "app2", "main.go"))
+			},
+			assert: func(t *testing.T, res monogo.DetectRes) {
+				require.Nil(t, findEntrypoint(res.Entrypoints, "cmd/app1"))
+				require.True(t, findEntrypoint(res.Entrypoints, "cmd/app2").Changed)
+				require.Equal(t, monogo.EntrypointRemoved, findEntrypoint(res.Entrypoints, "cmd/app2").Status)
+				require.Equal(t, []monogo.ChangeReason{monogo.EntrypointRemovedReason}, findEntrypoint(res.Entrypoints, "cmd/app2").Reasons)
+			},
+		},
+		{
+			name: "should detect moved entrypoints",
+			fields: fields{
+				showUnchanged: false,
+				discover:      true,
+			},
+			prepare: func(t *testing.T, w *git.Worktree) {
+				commitRename(t, w, filepath.Join("cmd", "app2", "main.go"), filepath.Join("cmd", "app2-renamed", "main.go"))
+			},
+			assert: func(t *testing.T, res monogo.DetectRes) {
+				require.Nil(t, findEntrypoint(res.Entrypoints, "cmd/app2"))
+				require.True(t, findEntrypoint(res.Entrypoints, "cmd/app2-renamed").Changed)
+				require.Equal(t, monogo.EntrypointMoved, findEntrypoint(res.Entrypoints, "cmd/app2-renamed").Status)
+				require.Equal(t, "cmd/app2", findEntrypoint(res.Entrypoints, "cmd/app2-renamed").MovedFrom)
+				require.Equal(t, monogo.EntrypointMovedReason, findEntrypoint(res.Entrypoints, "cmd/app2-renamed").Reasons[0])
+			},
+		},
 		{
 			name: "should detect external dependency version bump",
 			fields: fields{
@@ -130,6 +518,9 @@ func TestDetector_Run(t *testing.T) {
 				require.Contains(t, findEntrypoint(res.Entrypoints, "cmd/app2").Reasons, monogo.DependenciesChangedReason)
 				require.True(t, findEntrypoint(res.Entrypoints, "cmd/app3").Changed)
 				require.Contains(t, findEntrypoint(res.Entrypoints, "cmd/app3").Reasons, monogo.DependenciesChangedReason)
+				require.Equal(t, []monogo.DetectDependencyRes{
+					{Path: "go.uber.org/zap", Old: "v1.27.0", New: "v1.28.0", Bump: mod.BumpMinor},
+				}, findEntrypoint(res.Entrypoints, "cmd/app1").Dependencies)
 			},
 		},
 		{
@@ -305,11 +696,113 @@ func main() {
 				require.Nil(t, findEntrypoint(res.Entrypoints, "cmd/app3"))
 				// new app should be detected as changed (since it doesn't exist in main)
 				require.True(t, findEntrypoint(res.Entrypoints, "cmd/app4").Changed)
+				require.Equal(t, monogo.EntrypointAdded, findEntrypoint(res.Entrypoints, "cmd/app4").Status)
+				require.Contains(t, findEntrypoint(res.Entrypoints, "cmd/app4").Reasons, monogo.EntrypointAddedReason)
 				require.Contains(t, findEntrypoint(res.Entrypoints, "cmd/app4").Reasons, monogo.CreatedDeletedFilesReasons)
 				require.Contains(t, res.Git.Files.Created.All, "cmd/app4/main.go")
 				require.Contains(t, res.Git.Files.Created.Go, "cmd/app4/main.go")
 			},
 		},
+		{
+			name: "should explain reasons with files, packages and import chains",
+			fields: fields{
+				entrypoints: []string{"cmd/app1", "cmd/app2", "cmd/app3"},
+				explain:     true,
+			},
+			prepare: func(t *testing.T, w *git.Worktree) {
+				commitFileReplace(t, w, "pkg/pkgA/a.go", `"a"`, `"changed"`)
+				commitFile(t, w, "pkg/pkgA/new.go", "package pkgA\n\nfunc New() string {\n\treturn \"new\"\n}\n")
+				commitRemove(t, w, "pkg/pkgA/deleteme.go")
+			},
+			assert: func(t *testing.T, res monogo.DetectRes) {
+				require.Nil(t, findEntrypoint(res.Entrypoints, "cmd/app2"))
+				require.Equal(t, []monogo.DetectEvidenceRes{
+					{
+						Reason: monogo.ChangedFilesReason,
+						Files: []monogo.DetectFileEvidenceRes{
+							{Path: "pkg/pkgA/a.go", Package: "test-project/pkg/pkgA", Chain: []string{"test-project/cmd/app1", "test-project/pkg/pkgA"}},
+							{Path: "pkg/pkgA/new.go", Package: "test-project/pkg/pkgA", Chain: []string{"test-project/cmd/app1", "test-project/pkg/pkgA"}},
+						},
+					},
+					{
+						Reason:  monogo.CreatedDeletedFilesReasons,
+						Created: []string{"pkg/pkgA/new.go"},
+						Deleted: []string{"pkg/pkgA/deleteme.go"},
+					},
+				}, findEntrypoint(res.Entrypoints, "cmd/app1").Evidence)
+			},
+		},
+		{
+			name: "should explain global reasons",
+			fields: fields{
+				entrypoints:     []string{"cmd/app1", "cmd/app2", "cmd/app3"},
+				toolchainPolicy: mod.PolicyNone,
+				explain:         true,
+			},
+			prepare: func(t *testing.T, w *git.Worktree) {
+				commitFileReplace(t, w, "go.mod", "go 1.22", "go 1.23")
+			},
+			assert: func(t *testing.T, res monogo.DetectRes) {
+				require.Equal(t, []monogo.DetectEvidenceRes{
+					{Reason: monogo.GoVersionChangedReason, Files: []monogo.DetectFileEvidenceRes{{Path: "go.mod"}}},
+				}, findEntrypoint(res.Entrypoints, "cmd/app2").Evidence)
+			},
+		},
+		{
+			name: "should pass entrypoint metadata through",
+			fields: fields{
+				entrypoints:   []string{"./cmd/app1", "cmd/app2"},
+				showUnchanged: true,
+				metadata: map[string]monogo.EntrypointMetadata{
+					"cmd/app1": {Name: "app1", Attributes: map[string]string{"image": "ghcr.io/org/app1"}},
+				},
+			},
+			prepare: func(t *testing.T, w *git.Worktree) {
+				commitFileReplace(t, w, "pkg/pkgA/a.go", `"a"`, `"changed"`)
+			},
+			assert: func(t *testing.T, res monogo.DetectRes) {
+				app1 := findEntrypoint(res.Entrypoints, "./cmd/app1")
+				require.Equal(t, "app1", app1.Name)
+				require.Equal(t, map[string]string{"image": "ghcr.io/org/app1"}, app1.Attributes)
+				require.Empty(t, findEntrypoint(res.Entrypoints, "cmd/app2").Name)
+				require.Empty(t, findEntrypoint(res.Entrypoints, "cmd/app2").Attributes)
+			},
+		},
+		{
+			name: "should detect changed libraries separately",
+			fields: fields{
+				entrypoints: []string{"cmd/app1", "cmd/app2"},
+				libraries:   []string{"./pkg/...", "pkg/pkgB"},
+			},
+			prepare: func(t *testing.T, w *git.Worktree) {
+				commitFileReplace(t, w, "pkg/pkgA/a.go", `"a"`, `"changed"`)
+			},
+			assert: func(t *testing.T, res monogo.DetectRes) {
+				require.True(t, res.Changed)
+				require.True(t, res.LibrariesChanged)
+				require.True(t, findEntrypoint(res.Entrypoints, "cmd/app1").Changed)
+				require.Nil(t, findEntrypoint(res.Entrypoints, "./pkg/..."))
+				require.Len(t, res.Libraries, 1)
+				require.True(t, findEntrypoint(res.Libraries, "./pkg/...").Changed)
+				require.Equal(t, []monogo.ChangeReason{monogo.ChangedFilesReason}, findEntrypoint(res.Libraries, "./pkg/...").Reasons)
+			},
+		},
+		{
+			name: "should detect added libraries",
+			fields: fields{
+				entrypoints: []string{"cmd/app1"},
+				libraries:   []string{"pkg/sdk/..."},
+			},
+			prepare: func(t *testing.T, w *git.Worktree) {
+				commitFile(t, w, "pkg/sdk/client/client.go", "package client\n")
+			},
+			assert: func(t *testing.T, res monogo.DetectRes) {
+				require.Empty(t, res.Entrypoints)
+				require.False(t, res.Changed)
+				require.True(t, res.LibrariesChanged)
+				require.Equal(t, monogo.EntrypointAdded, findEntrypoint(res.Libraries, "pkg/sdk/...").Status)
+			},
+		},
 		{
 			name: "should show unchanged entrypoints when showUnchanged is true",
 			fields: fields{
@@ -339,6 +832,7 @@ func main() {
 				// app2 should be unchanged but included
 				require.False(t, findEntrypoint(res.Entrypoints, "cmd/app2").Changed)
 				require.Empty(t, findEntrypoint(res.Entrypoints, "cmd/app2").Reasons)
+				require.Nil(t, findEntrypoint(res.Entrypoints, "cmd/app1").Evidence)
 				require.Contains(t, res.Git.Files.Created.All, "pkg/pkgA/new.go")
 				require.Contains(t, res.Git.Files.Created.Go, "pkg/pkgA/new.go")
 			},
@@ -350,47 +844,40 @@ func main() {
 		t.Run(tt.name, func(t *testing.T) {
 			// t.Parallel()
 
-			// setup folder
-			tmpDir := filepath.Join("./tmp", t.Name())
-			require.NoError(t, os.RemoveAll(tmpDir))
-			require.NoError(t, os.MkdirAll(tmpDir, 0o755))
-			require.NoError(t, os.CopyFS(tmpDir, os.DirFS("./testdata/test-project")))
-
-			// setup git
-			repo, err := git.PlainInitWithOptions(tmpDir, &git.PlainInitOptions{
-				InitOptions: git.InitOptions{DefaultBranch: plumbing.NewBranchReferenceName("main")},
-			})
-			require.NoError(t, err)
-			w, err := repo.Worktree()
-			require.NoError(t, err)
-
-			// initial commit
-			_, err = w.Add(".")
-			require.NoError(t, err)
-			_, err = w.Commit("initial commit", &git.CommitOptions{
-				Author: testAuthor,
-			})
-			require.NoError(t, err)
-
-			// checkout to a new branch based on main
-			ref, err := repo.Head()
-			require.NoError(t, err)
-			b := plumbing.NewBranchReferenceName("test-branch")
-			require.NoError(t, w.Checkout(&git.CheckoutOptions{
-				Create: true,
-				Branch: b,
-				Hash:   ref.Hash(),
-			}))
+			tmpDir, w, b := setupTestRepo(t)
 
 			// run detector
 			g, err := xgit.New(xgit.WithPath(tmpDir))
 			require.NoError(t, err)
-			d := monogo.NewDetector(tt.fields.entrypoints, slog.Default(), g,
+			opts := []monogo.WithDetectOpt{
 				monogo.WithPath(tmpDir),
 				monogo.WithBaseRef(string(plumbing.NewBranchReferenceName("main"))),
 				monogo.WithCompareRef(string(b)),
 				monogo.WithShowUnchanged(tt.fields.showUnchanged),
-			)
+				monogo.WithIgnore(tt.fields.ignore),
+				monogo.WithEntrypointIgnore(tt.fields.entrypointIgnore),
+				monogo.WithTriggers(tt.fields.triggers),
+				monogo.WithInputs(tt.fields.inputs),
+				monogo.WithExplain(tt.fields.explain),
+				monogo.WithMetadata(tt.fields.metadata),
+				monogo.WithLibraries(tt.fields.libraries),
+			}
+			if tt.fields.goVersionPolicy != "" {
+				opts = append(opts, monogo.WithGoVersionPolicy(tt.fields.goVersionPolicy))
+			}
+			if tt.fields.toolchainPolicy != "" {
+				opts = append(opts, monogo.WithToolchainPolicy(tt.fields.toolchainPolicy))
+			}
+			if len(tt.fields.godebugScope) > 0 {
+				opts = append(opts, monogo.WithGodebugScope(tt.fields.godebugScope))
+			}
+			if tt.fields.configFile {
+				opts = append(opts, monogo.WithConfigFile(filepath.Join(tmpDir, monogo.DefaultConfigFile)))
+			}
+			if tt.fields.discover {
+				opts = append(opts, monogo.WithDiscover(tt.fields.discoverGlobs))
+			}
+			d := monogo.NewDetector(tt.fields.entrypoints, slog.Default(), g, opts...)
 
 			tt.prepare(t, w)
 			res, err := d.Run(context.Background())
@@ -399,3 +886,238 @@ func main() {
 		})
 	}
 }
+
+func TestDetector_Run_MissingEntrypoint(t *testing.T) {
+	tmpDir, w, b := setupTestRepo(t)
+	commitFile(t, w, "README.md", "# test")
+
+	g, err := xgit.New(xgit.WithPath(tmpDir))
+	require.NoError(t, err)
+	d := monogo.NewDetector([]string{"cmd/app1", "cmd/missing"}, slog.Default(), g,
+		monogo.WithPath(tmpDir),
+		monogo.WithCompareRef(string(b)),
+	)
+
+	_, err = d.Run(context.Background())
+	require.ErrorContains(t, err, "entrypoint cmd/missing not found in base nor compare refs")
+}
+
+func TestDetectEntrypointRes_JSON(t *testing.T) {
+	entry := monogo.DetectEntrypointRes{
+		Path:         "cmd/app1",
+		Name:         "app1",
+		Changed:      true,
+		Reasons:      []monogo.ChangeReason{monogo.ChangedFilesReason},
+		Dependencies: []monogo.DetectDependencyRes{},
+		Inputs:       []string{},
+		Ignored:      []string{},
+		Attributes:   map[string]string{"image": "ghcr.io/org/app1", "team": "payments"},
+	}
+
+	data, err := json.Marshal(entry)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"path": "cmd/app1",
+		"name": "app1",
+		"changed": true,
+		"reasons": ["files changed"],
+		"dependencies": [],
+		"inputs": [],
+		"ignored": [],
+		"image": "ghcr.io/org/app1",
+		"team": "payments"
+	}`, string(data))
+
+	var decoded monogo.DetectEntrypointRes
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, entry, decoded)
+}
+
+func TestDetector_Run_Patch(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.CopyFS(dir, os.DirFS("./testdata/test-project")))
+
+	// The patch must already be applied to the tree
+	diff := `diff --git a/pkg/pkgA/new.go b/pkg/pkgA/new.go
+new file mode 100644
+--- /dev/null
++++ b/pkg/pkgA/new.go
+@@ -0,0 +1 @@
++package pkgA
+diff --git a/go.mod b/go.mod
+--- a/go.mod
++++ b/go.mod
+@@ -1,3 +1,3 @@
+ module test-project
+ 
+-go 1.22
++go 1.23
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "pkg/pkgA/new.go"), []byte("package pkgA\n"), 0o600))
+	goMod, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), bytes.Replace(goMod, []byte("go 1.22"), []byte("go 1.23"), 1), 0o600))
+
+	source, err := patch.NewSource(dir, strings.NewReader(diff))
+	require.NoError(t, err)
+	d := monogo.NewDetector([]string{"cmd/app1", "cmd/app2"}, slog.Default(), nil,
+		monogo.WithPath(dir),
+		monogo.WithSource(source),
+		monogo.WithBaseRef(patch.BaseRef),
+		monogo.WithCompareRef(patch.CompareRef),
+		monogo.WithToolchainPolicy(mod.PolicyNone),
+	)
+
+	res, err := d.Run(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []string{"pkg/pkgA/new.go"}, res.Git.Files.Created.All)
+	require.Equal(t, []string{"go.mod"}, res.Git.Files.Updated.All)
+	require.Equal(t, []monogo.ChangeReason{monogo.GoVersionChangedReason}, findEntrypoint(res.Entrypoints, "cmd/app1").Reasons)
+	require.Equal(t, []monogo.ChangeReason{monogo.GoVersionChangedReason}, findEntrypoint(res.Entrypoints, "cmd/app2").Reasons)
+}
+
+func TestDetector_Run_PatchFiles(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.CopyFS(dir, os.DirFS("./testdata/test-project")))
+
+	// pkg/pkgA/deleteme.go is deleted and pkg/pkgB/b.go is renamed, with the patch already applied
+	deleted, err := os.ReadFile(filepath.Join(dir, "pkg/pkgA/deleteme.go"))
+	require.NoError(t, err)
+	require.NoError(t, os.Remove(filepath.Join(dir, "pkg/pkgA/deleteme.go")))
+	require.NoError(t, os.Rename(filepath.Join(dir, "pkg/pkgB/b.go"), filepath.Join(dir, "pkg/pkgB/renamed.go")))
+
+	diff := "diff --git a/pkg/pkgA/deleteme.go b/pkg/pkgA/deleteme.go\ndeleted file mode 100644\n--- a/pkg/pkgA/deleteme.go\n+++ /dev/null\n"
+	lines := strings.Split(strings.TrimSuffix(string(deleted), "\n"), "\n")
+	diff += fmt.Sprintf("@@ -1,%d +0,0 @@\n-%s\n", len(lines), strings.Join(lines, "\n-"))
+	diff += "diff --git a/pkg/pkgB/b.go b/pkg/pkgB/renamed.go\nsimilarity index 100%\nrename from pkg/pkgB/b.go\nrename to pkg/pkgB/renamed.go\n"
+
+	source, err := patch.NewSource(dir, strings.NewReader(diff))
+	require.NoError(t, err)
+	d := monogo.NewDetector([]string{"cmd/app1", "cmd/app2"}, slog.Default(), nil,
+		monogo.WithPath(dir),
+		monogo.WithSource(source),
+		monogo.WithBaseRef(patch.BaseRef),
+		monogo.WithCompareRef(patch.CompareRef),
+	)
+
+	res, err := d.Run(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []monogo.ChangeReason{monogo.CreatedDeletedFilesReasons}, findEntrypoint(res.Entrypoints, "cmd/app1").Reasons)
+	require.Equal(t, []monogo.ChangeReason{monogo.ChangedFilesReason, monogo.CreatedDeletedFilesReasons}, findEntrypoint(res.Entrypoints, "cmd/app2").Reasons)
+}
+
+func TestDetector_Run_Dirs(t *testing.T) {
+	base, compare := t.TempDir(), t.TempDir()
+	require.NoError(t, os.CopyFS(base, os.DirFS("./testdata/test-project")))
+	require.NoError(t, os.CopyFS(compare, os.DirFS("./testdata/test-project")))
+	require.NoError(t, os.WriteFile(filepath.Join(compare, "pkg/pkgB/new.go"), []byte("package pkgB\n"), 0o600))
+
+	source, err := dirs.NewSource(base, compare)
+	require.NoError(t, err)
+	d := monogo.NewDetector([]string{"cmd/app1", "cmd/app2", "cmd/app3"}, slog.Default(), nil,
+		monogo.WithPath(compare),
+		monogo.WithSource(source),
+		monogo.WithBaseRef(dirs.BaseRef),
+		monogo.WithCompareRef(dirs.CompareRef),
+	)
+
+	res, err := d.Run(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []string{"pkg/pkgB/new.go"}, res.Git.Files.Created.All)
+	require.Len(t, res.Entrypoints, 2)
+	require.NotNil(t, findEntrypoint(res.Entrypoints, "cmd/app3"))
+	require.Equal(t, []monogo.ChangeReason{monogo.ChangedFilesReason, monogo.CreatedDeletedFilesReasons}, findEntrypoint(res.Entrypoints, "cmd/app2").Reasons)
+}
+
+// refRecorder records the refs made available by the source
+type refRecorder struct {
+	monogo.Source
+	refs []string
+}
+
+func (s *refRecorder) RunOnRef(ref string, cb func(dir string) error) error {
+	s.refs = append(s.refs, ref)
+	return s.Source.RunOnRef(ref, cb)
+}
+
+func TestDetector_Run_TriggersSkipBase(t *testing.T) {
+	base, compare := t.TempDir(), t.TempDir()
+	require.NoError(t, os.CopyFS(base, os.DirFS("./testdata/test-project")))
+	require.NoError(t, os.CopyFS(compare, os.DirFS("./testdata/test-project")))
+	require.NoError(t, os.WriteFile(filepath.Join(compare, "Makefile"), []byte("build:\n"), 0o600))
+
+	source, err := dirs.NewSource(base, compare)
+	require.NoError(t, err)
+	recorder := &refRecorder{Source: source}
+	d := monogo.NewDetector(nil, slog.Default(), nil,
+		monogo.WithPath(compare),
+		monogo.WithSource(recorder),
+		monogo.WithBaseRef(dirs.BaseRef),
+		monogo.WithCompareRef(dirs.CompareRef),
+		monogo.WithDiscover([]string{"cmd/*"}),
+		monogo.WithTriggers([]string{"Makefile"}),
+		monogo.WithExplain(true),
+	)
+
+	res, err := d.Run(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []string{dirs.CompareRef}, recorder.refs)
+	require.True(t, res.Changed)
+	require.Positive(t, res.Stats.Duration)
+	require.Len(t, res.Entrypoints, 3)
+
+	app1 := findEntrypoint(res.Entrypoints, "cmd/app1")
+	require.Equal(t, []monogo.ChangeReason{monogo.GlobalTriggerReason}, app1.Reasons)
+	require.Equal(t, []string{"Makefile"}, app1.Triggers)
+	require.Equal(t, []monogo.DetectEvidenceRes{
+		{Reason: monogo.GlobalTriggerReason, Files: []monogo.DetectFileEvidenceRes{{Path: "Makefile"}}},
+	}, app1.Evidence)
+}
+
+func TestDetector_Run_DirsMoved(t *testing.T) {
+	base, compare := t.TempDir(), t.TempDir()
+	require.NoError(t, os.CopyFS(base, os.DirFS("./testdata/test-project")))
+	require.NoError(t, os.CopyFS(compare, os.DirFS("./testdata/test-project")))
+	require.NoError(t, os.Rename(filepath.Join(compare, "cmd/app2"), filepath.Join(compare, "cmd/app2-renamed")))
+
+	source, err := dirs.NewSource(base, compare)
+	require.NoError(t, err)
+	d := monogo.NewDetector(nil, slog.Default(), nil,
+		monogo.WithPath(compare),
+		monogo.WithSource(source),
+		monogo.WithBaseRef(dirs.BaseRef),
+		monogo.WithCompareRef(dirs.CompareRef),
+		monogo.WithDiscover([]string{"cmd/*"}),
+	)
+
+	res, err := d.Run(context.Background())
+	require.NoError(t, err)
+	require.Nil(t, findEntrypoint(res.Entrypoints, "cmd/app2"))
+	require.Equal(t, monogo.EntrypointMoved, findEntrypoint(res.Entrypoints, "cmd/app2-renamed").Status)
+	require.Equal(t, "cmd/app2", findEntrypoint(res.Entrypoints, "cmd/app2-renamed").MovedFrom)
+}
+
+func TestDetector_Run_Cache(t *testing.T) {
+	tmpDir, w, b := setupTestRepo(t)
+	commitFile(t, w, "pkg/pkgB/b.go", "package pkgB\n\nfunc B() string { return \"changed\" }\n")
+
+	g, err := xgit.New(xgit.WithPath(tmpDir))
+	require.NoError(t, err)
+	c, err := diskcache.New(t.TempDir())
+	require.NoError(t, err)
+	d := monogo.NewDetector([]string{"cmd/app1", "cmd/app2", "cmd/app3"}, slog.Default(), g,
+		monogo.WithPath(tmpDir),
+		monogo.WithCompareRef(string(b)),
+		monogo.WithCache(c),
+	)
+
+	// The warm run loads the packages from the cache, with the same results
+	cold, err := d.Run(context.Background())
+	require.NoError(t, err)
+	warm, err := d.Run(context.Background())
+	require.NoError(t, err)
+
+	require.ElementsMatch(t, cold.Entrypoints, warm.Entrypoints)
+	require.Len(t, warm.Entrypoints, 2)
+	require.Equal(t, []monogo.ChangeReason{monogo.ChangedFilesReason}, findEntrypoint(warm.Entrypoints, "cmd/app2").Reasons)
+}
@@ -0,0 +1,180 @@
+package dirs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/brunoluiz/monogo/git"
+	"github.com/samber/lo"
+)
+
+const (
+	// BaseRef is the tree in the base directory
+	BaseRef = "dirs:base"
+	// CompareRef is the tree in the compare directory
+	CompareRef = "dirs:compare"
+)
+
+// Source provides two directory snapshots of the same repository (eg: extracted release tarballs), so changes
+// can be detected without git. Files are compared by content hash.
+type Source struct {
+	base    tree
+	compare tree
+}
+
+// tree contains the content hash of each file, by slash separated path relative to the directory
+type tree struct {
+	dir    string
+	hash   string
+	hashes map[string]string
+}
+
+func NewSource(base, compare string) (*Source, error) {
+	baseTree, err := readTree(base)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read base dir: %w", err)
+	}
+
+	compareTree, err := readTree(compare)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read compare dir: %w", err)
+	}
+
+	return &Source{base: baseTree, compare: compareTree}, nil
+}
+
+// Ref returns the hash of the whole tree for the ref, computed from the file paths and their content
+func (s *Source) Ref(ref string) (string, string, error) {
+	t, err := s.tree(ref)
+	if err != nil {
+		return "", "", err
+	}
+	return t.hash, ref, nil
+}
+
+// TreeHash returns the hash of the whole tree for the ref, as Ref does
+func (s *Source) TreeHash(ref string) (string, error) {
+	t, err := s.tree(ref)
+	return t.hash, err
+}
+
+// TreeFiles returns the content hash of each file in the tree for the ref, by path
+func (s *Source) TreeFiles(ref string) (map[string]string, error) {
+	t, err := s.tree(ref)
+	return t.hashes, err
+}
+
+// Diff returns the files created, updated and deleted from the base to the compare directory. Renames are
+// only detected for files with the same content.
+func (s *Source) Diff(fromRef, compareRef string) (git.DiffResult, error) {
+	if fromRef != CompareRef || compareRef != BaseRef {
+		return git.DiffResult{}, fmt.Errorf("directory diffs are only available from %s to %s", CompareRef, BaseRef)
+	}
+	return git.DiffFiles(s.base.hashes, s.compare.hashes), nil
+}
+
+// RunOnRef runs cb on the directory of the ref. Directories are used as they are, without copies.
+func (s *Source) RunOnRef(ref string, cb func(dir string) error) error {
+	t, err := s.tree(ref)
+	if err != nil {
+		return err
+	}
+	return cb(t.dir)
+}
+
+func (s *Source) tree(ref string) (tree, error) {
+	switch ref {
+	case BaseRef:
+		return s.base, nil
+	case CompareRef:
+		return s.compare, nil
+	default:
+		return tree{}, fmt.Errorf("unknown directory ref %s: must be %s or %s", ref, BaseRef, CompareRef)
+	}
+}
+
+// readTree hashes the files within dir, skipping the .git directory. Symlinks are hashed by their target.
+func readTree(dir string) (tree, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return tree{}, err
+	}
+	if !info.IsDir() {
+		return tree{}, fmt.Errorf("%s is not a directory", dir)
+	}
+
+	hashes := map[string]string{}
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case d.IsDir() && d.Name() == ".git":
+			return filepath.SkipDir
+		case d.IsDir():
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		var hash string
+		switch {
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			hash = hashBytes([]byte("symlink:" + link))
+		case d.Type().IsRegular():
+			if hash, err = hashFile(path); err != nil {
+				return err
+			}
+		default:
+			return nil
+		}
+
+		hashes[filepath.ToSlash(rel)] = hash
+		return nil
+	})
+	if err != nil {
+		return tree{}, err
+	}
+
+	h := sha256.New()
+	paths := lo.Keys(hashes)
+	slices.Sort(paths)
+	for _, path := range paths {
+		fmt.Fprintf(h, "%s\x00%s\n", path, hashes[path])
+	}
+
+	return tree{dir: dir, hash: hex.EncodeToString(h.Sum(nil)), hashes: hashes}, nil
+}
+
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func hashBytes(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
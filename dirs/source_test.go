@@ -0,0 +1,61 @@
+package dirs_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/brunoluiz/monogo/dirs"
+	"github.com/brunoluiz/monogo/git"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSource(t *testing.T) {
+	base, compare := t.TempDir(), t.TempDir()
+	write := func(dir, path, content string) {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, path)), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, path), []byte(content), 0o600))
+	}
+	write(base, "go.mod", "module foo\n")
+	write(base, "pkg/a.go", "package pkg\n\nconst A = \"a\"\n")
+	write(base, "pkg/old.go", "package pkg\n")
+	write(base, ".git/HEAD", "ref: refs/heads/main\n")
+	write(compare, "go.mod", "module foo\n")
+	write(compare, "pkg/a.go", "package pkg\n\nconst A = \"b\"\n")
+	write(compare, "pkg/new.go", "package pkg\n")
+	write(compare, ".git/HEAD", "ref: refs/heads/release\n")
+
+	s, err := dirs.NewSource(base, compare)
+	require.NoError(t, err)
+
+	diff, err := s.Diff(dirs.CompareRef, dirs.BaseRef)
+	require.NoError(t, err)
+	require.Equal(t, git.DiffResult{
+		Created: []string{"pkg/new.go"},
+		Updated: []string{"pkg/a.go"},
+		Deleted: []string{"pkg/old.go"},
+		Renamed: map[string]string{"pkg/new.go": "pkg/old.go"},
+	}, diff)
+
+	baseHash, _, err := s.Ref(dirs.BaseRef)
+	require.NoError(t, err)
+	compareHash, name, err := s.Ref(dirs.CompareRef)
+	require.NoError(t, err)
+	require.Equal(t, dirs.CompareRef, name)
+	require.NotEqual(t, baseHash, compareHash)
+
+	for ref, expected := range map[string]string{dirs.BaseRef: base, dirs.CompareRef: compare} {
+		err = s.RunOnRef(ref, func(dir string) error {
+			require.Equal(t, expected, dir)
+			return nil
+		})
+		require.NoError(t, err)
+	}
+
+	_, _, err = s.Ref("refs/heads/main")
+	require.Error(t, err)
+	_, err = s.Diff(dirs.BaseRef, dirs.CompareRef)
+	require.Error(t, err)
+	_, err = dirs.NewSource(base, filepath.Join(compare, "go.mod"))
+	require.Error(t, err)
+}
@@ -0,0 +1,79 @@
+package diskcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Cache stores JSON documents in a directory, one file per key. It is safe for concurrent use, including by
+// other processes sharing the directory, as entries are written atomically.
+type Cache struct {
+	dir string
+}
+
+func New(dir string) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cache dir: %w", err)
+	}
+	return &Cache{dir: dir}, nil
+}
+
+// Key returns a key identifying all the parts, such as a tree hash and the file checksums it depends on
+func Key(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// Get decodes the entry into v, reporting whether it was found. Entries which can't be decoded, such as the
+// ones written by other versions, are reported as not found.
+func (c *Cache) Get(key string, v any) (bool, error) {
+	data, err := os.ReadFile(c.path(key))
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return false, nil
+	case err != nil:
+		return false, fmt.Errorf("failed to read cache entry: %w", err)
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return false, nil // nolint:nilerr
+	}
+	return true, nil
+}
+
+// Put encodes v as the entry, replacing any previous one
+func (c *Cache) Put(key string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(c.dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create cache entry: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), c.path(key)); err != nil {
+		return fmt.Errorf("failed to store cache entry: %w", err)
+	}
+	return nil
+}
+
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
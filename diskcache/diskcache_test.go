@@ -0,0 +1,43 @@
+package diskcache_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/brunoluiz/monogo/diskcache"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "cache")
+	c, err := diskcache.New(dir)
+	require.NoError(t, err)
+
+	type entry struct {
+		Files []string `json:"files"`
+	}
+	key := diskcache.Key("tree", "go.mod")
+	require.NotEqual(t, key, diskcache.Key("tree", "go.sum"))
+
+	var got entry
+	found, err := c.Get(key, &got)
+	require.NoError(t, err)
+	require.False(t, found)
+
+	require.NoError(t, c.Put(key, entry{Files: []string{"a.go"}}))
+	found, err = c.Get(key, &got)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, entry{Files: []string{"a.go"}}, got)
+
+	// Corrupted entries are misses, so they are written again
+	require.NoError(t, os.WriteFile(filepath.Join(dir, key+".json"), []byte("{"), 0o600))
+	found, err = c.Get(key, &got)
+	require.NoError(t, err)
+	require.False(t, found)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+}
@@ -1,8 +1,11 @@
 package git
 
 import (
+	"context"
 	"fmt"
+	"slices"
 	"sort"
+	"time"
 
 	git "github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
@@ -18,6 +21,8 @@ type DiffResult struct {
 	Created []string
 	Updated []string
 	Deleted []string
+	// Renamed maps renamed files to their previous name. They are also listed as Created and Deleted.
+	Renamed map[string]string
 }
 
 func (d DiffResult) All() []string {
@@ -29,6 +34,39 @@ func (d DiffResult) All() []string {
 	return all
 }
 
+// DiffFiles returns the changes from the base to the compare files, given the content hash of each file by path.
+// Renames are only detected for files with the same content, unlike git which also detects similar ones.
+func DiffFiles(base, compare map[string]string) DiffResult {
+	result := DiffResult{Created: []string{}, Updated: []string{}, Deleted: []string{}, Renamed: map[string]string{}}
+	for path, hash := range compare {
+		baseHash, ok := base[path]
+		switch {
+		case !ok:
+			result.Created = append(result.Created, path)
+		case baseHash != hash:
+			result.Updated = append(result.Updated, path)
+		}
+	}
+
+	deletedByHash := map[string][]string{}
+	for path, hash := range base {
+		if _, ok := compare[path]; !ok {
+			result.Deleted = append(result.Deleted, path)
+			deletedByHash[hash] = append(deletedByHash[hash], path)
+		}
+	}
+	for _, path := range result.Created {
+		if deleted := deletedByHash[compare[path]]; len(deleted) == 1 {
+			result.Renamed[path] = deleted[0]
+		}
+	}
+
+	slices.Sort(result.Created)
+	slices.Sort(result.Updated)
+	slices.Sort(result.Deleted)
+	return result
+}
+
 type gitConfig struct {
 	path string
 }
@@ -77,6 +115,104 @@ func (g *Git) Ref(ref string) (string, string, error) {
 	return refResolved.String(), ref, nil
 }
 
+// commitFor returns the commit a specific ref resolves to
+func (g *Git) commitFor(ref string) (*object.Commit, error) {
+	refResolved, err := g.repo.ResolveRevision(plumbing.Revision(ref))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve ref %s: %w", ref, err)
+	}
+
+	commit, err := g.repo.CommitObject(*refResolved)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit for ref %s: %w", ref, err)
+	}
+	return commit, nil
+}
+
+// TreeHash returns the hash of the tree for a specific ref, which identifies its content
+func (g *Git) TreeHash(ref string) (string, error) {
+	commit, err := g.commitFor(ref)
+	if err != nil {
+		return "", err
+	}
+
+	return commit.TreeHash.String(), nil
+}
+
+// TreeFiles returns the blob hash of every file in the tree for a specific ref, by path
+func (g *Git) TreeFiles(ref string) (map[string]string, error) {
+	commit, err := g.commitFor(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tree for ref %s: %w", ref, err)
+	}
+
+	files := map[string]string{}
+	err = tree.Files().ForEach(func(f *object.File) error {
+		files[f.Name] = f.Hash.String()
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files for ref %s: %w", ref, err)
+	}
+	return files, nil
+}
+
+// WorktreeChanges returns the files changed in the worktree compared to a specific ref, including the
+// uncommitted and untracked ones. Files ignored by git are left out.
+func (g *Git) WorktreeChanges(ref string) ([]string, error) {
+	wt, err := g.repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve worktree: %w", err)
+	}
+
+	head, err := g.repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve head ref: %w", err)
+	}
+
+	committed, err := g.Diff(head.Hash().String(), ref)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := wt.Status()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get worktree status: %w", err)
+	}
+
+	files := map[string]bool{}
+	for _, file := range committed.All() {
+		files[file] = true
+	}
+	for file, s := range status {
+		if s.Worktree != git.Unmodified || s.Staging != git.Unmodified {
+			files[file] = true
+		}
+	}
+
+	out := make([]string, 0, len(files))
+	for file := range files {
+		out = append(out, file)
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// CommitTime returns the committer time for a specific ref
+func (g *Git) CommitTime(ref string) (time.Time, error) {
+	commit, err := g.commitFor(ref)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return commit.Committer.When, nil
+}
+
 func (g *Git) RunOnRef(ref string, cb func() error) error {
 	wt, err := g.repo.Worktree()
 	if err != nil {
@@ -136,12 +272,12 @@ func (g *Git) Diff(fromRef, compareRef string) (DiffResult, error) {
 		return DiffResult{}, fmt.Errorf("failed to get compare tree: %w", err)
 	}
 
-	changes, err := object.DiffTree(compareTree, fromTree)
+	changes, err := object.DiffTreeWithOptions(context.Background(), compareTree, fromTree, object.DefaultDiffTreeOptions)
 	if err != nil {
 		return DiffResult{}, fmt.Errorf("failed to diff: %w", err)
 	}
 
-	result := DiffResult{Created: []string{}, Updated: []string{}, Deleted: []string{}}
+	result := DiffResult{Created: []string{}, Updated: []string{}, Deleted: []string{}, Renamed: map[string]string{}}
 	for _, change := range changes {
 		action, err := change.Action()
 		if err != nil {
@@ -159,6 +295,13 @@ func (g *Git) Diff(fromRef, compareRef string) (DiffResult, error) {
 		case merkletrie.Delete:
 			result.Deleted = append(result.Deleted, change.From.Name)
 		case merkletrie.Modify:
+			// Renames are reported as a deleted and a created file, as both paths are relevant to the walkers
+			if change.From.Name != change.To.Name {
+				result.Created = append(result.Created, change.To.Name)
+				result.Deleted = append(result.Deleted, change.From.Name)
+				result.Renamed[change.To.Name] = change.From.Name
+				continue
+			}
 			result.Updated = append(result.Updated, name)
 		}
 	}
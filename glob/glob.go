@@ -0,0 +1,193 @@
+package glob
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Match reports whether name matches the slash separated pattern. It supports
+// the same syntax as path.Match, with the addition of `**` matching zero or more
+// directories. Leading `./` are ignored on both pattern and name.
+func Match(pattern, name string) bool {
+	return matchSegments(split(pattern), split(name))
+}
+
+// MatchAny reports whether name matches any of the patterns
+func MatchAny(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if Match(p, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter splits names between the ones matching any of the patterns and the ones that do not
+func Filter(patterns []string, names []string) (matched, unmatched []string) {
+	matched, unmatched = []string{}, []string{}
+	for _, name := range names {
+		if MatchAny(patterns, name) {
+			matched = append(matched, name)
+		} else {
+			unmatched = append(unmatched, name)
+		}
+	}
+	return matched, unmatched
+}
+
+// Glob returns the files under root matching any of the patterns, as slash separated paths relative
+// to root. The .git directory and directories no pattern can match files under are skipped.
+func Glob(root string, patterns []string) ([]string, error) {
+	matched, _, err := GlobDirs(root, patterns)
+	return matched, err
+}
+
+// GlobDirs works as Glob, also returning the modification time of each walked directory (taken before
+// reading it), by slash separated path relative to root. Files are only created, deleted or renamed
+// within walked directories when their modification time changes, so callers can glob again only then.
+func GlobDirs(root string, patterns []string) ([]string, map[string]time.Time, error) {
+	matched, dirs := []string{}, map[string]time.Time{}
+	if len(patterns) == 0 {
+		return matched, dirs, nil
+	}
+
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if d.IsDir() {
+			if d.Name() == ".git" || !matchAnyDir(patterns, rel) {
+				return filepath.SkipDir
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			dirs[rel] = info.ModTime()
+			return nil
+		}
+
+		if MatchAny(patterns, rel) {
+			matched = append(matched, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to walk %s: %w", root, err)
+	}
+	return matched, dirs, nil
+}
+
+// Select returns the names matching the patterns. Patterns prefixed with `!` exclude names, while
+// the others include them. If there are no include patterns, all names not excluded are selected.
+func Select(patterns []string, names []string) []string {
+	includes, excludes := []string{}, []string{}
+	for _, p := range patterns {
+		if exclude, ok := strings.CutPrefix(p, "!"); ok {
+			excludes = append(excludes, exclude)
+		} else {
+			includes = append(includes, p)
+		}
+	}
+
+	selected := []string{}
+	for _, name := range names {
+		if len(includes) > 0 && !MatchAny(includes, name) {
+			continue
+		}
+		if MatchAny(excludes, name) {
+			continue
+		}
+		selected = append(selected, name)
+	}
+	return selected
+}
+
+// Validate returns an error if the pattern is malformed
+func Validate(pattern string) error {
+	pattern = strings.TrimPrefix(pattern, "!")
+	if strings.TrimSpace(pattern) == "" {
+		return fmt.Errorf("empty pattern")
+	}
+	for _, segment := range split(pattern) {
+		if _, err := path.Match(segment, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// Clean normalises paths to the format used for matching (eg: ./cmd/app -> cmd/app)
+func Clean(name string) string {
+	return strings.TrimPrefix(path.Clean("/"+strings.TrimPrefix(name, "./")), "/")
+}
+
+func split(p string) []string {
+	p = Clean(p)
+	if p == "" {
+		return []string{}
+	}
+	return strings.Split(p, "/")
+}
+
+// matchAnyDir reports whether files under dir might match any of the patterns
+func matchAnyDir(patterns []string, dir string) bool {
+	for _, p := range patterns {
+		if matchDirSegments(split(p), split(dir)) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchDirSegments(pattern, dir []string) bool {
+	for ; len(dir) > 0; pattern, dir = pattern[1:], dir[1:] {
+		if len(pattern) == 0 {
+			return false
+		}
+		if pattern[0] == "**" {
+			return true
+		}
+		if ok, err := path.Match(pattern[0], dir[0]); err != nil || !ok {
+			return false
+		}
+	}
+	return len(pattern) > 0
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			// Collapse repeated `**` and try to match the rest against every suffix
+			rest := pattern[1:]
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(rest, name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+
+		if ok, err := path.Match(pattern[0], name[0]); err != nil || !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+
+	return len(name) == 0
+}
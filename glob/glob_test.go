@@ -0,0 +1,151 @@
+package glob_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/brunoluiz/monogo/glob"
+)
+
+func TestMatch(t *testing.T) {
+	testCases := []struct {
+		pattern  string
+		name     string
+		expected bool
+	}{
+		{pattern: "cmd/app", name: "cmd/app", expected: true},
+		{pattern: "./cmd/app", name: "cmd/app", expected: true},
+		{pattern: "cmd/app", name: "./cmd/app", expected: true},
+		{pattern: "cmd/*", name: "cmd/app", expected: true},
+		{pattern: "cmd/*", name: "cmd/app/sub", expected: false},
+		{pattern: "services/*/cmd/*", name: "services/billing/cmd/api", expected: true},
+		{pattern: "**/*.md", name: "README.md", expected: true},
+		{pattern: "**/*.md", name: "docs/guides/intro.md", expected: true},
+		{pattern: "docs/**", name: "docs/guides/intro.md", expected: true},
+		{pattern: "docs/**", name: "pkg/docs/intro.md", expected: false},
+		{pattern: "**/testdata/**", name: "pkg/a/testdata/file.txt", expected: true},
+		{pattern: "**/tools/**", name: "cmd/tools/gen", expected: true},
+		{pattern: "**/tools/**", name: "cmd/app", expected: false},
+		{pattern: "a/**/b", name: "a/b", expected: true},
+		{pattern: "a/**/b", name: "a/x/y/b", expected: true},
+		{pattern: "[", name: "[", expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.pattern+" "+tc.name, func(t *testing.T) {
+			if got := glob.Match(tc.pattern, tc.name); got != tc.expected {
+				t.Errorf("expected match to be %v, but got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestFilter(t *testing.T) {
+	matched, unmatched := glob.Filter([]string{"**/*.md", "docs/**"}, []string{"README.md", "docs/a.txt", "main.go"})
+	if !reflect.DeepEqual(matched, []string{"README.md", "docs/a.txt"}) {
+		t.Errorf("unexpected matched: %v", matched)
+	}
+	if !reflect.DeepEqual(unmatched, []string{"main.go"}) {
+		t.Errorf("unexpected unmatched: %v", unmatched)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		pattern string
+		valid   bool
+	}{
+		{pattern: "**/*.md", valid: true},
+		{pattern: "services/*/cmd/[a-z]*", valid: true},
+		{pattern: "", valid: false},
+		{pattern: "docs/[", valid: false},
+		{pattern: "a/[z-a/b", valid: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.pattern, func(t *testing.T) {
+			if err := glob.Validate(tc.pattern); (err == nil) != tc.valid {
+				t.Errorf("expected valid to be %v, but got error %v", tc.valid, err)
+			}
+		})
+	}
+}
+
+func TestSelect(t *testing.T) {
+	names := []string{"cmd/app", "services/billing/cmd/api", "services/billing/cmd/tools/gen", "tools/lint"}
+	testCases := []struct {
+		name     string
+		patterns []string
+		expected []string
+	}{
+		{name: "no patterns", patterns: nil, expected: names},
+		{name: "include", patterns: []string{"services/*/cmd/*"}, expected: []string{"services/billing/cmd/api"}},
+		{name: "exclude", patterns: []string{"!**/tools/**"}, expected: []string{"cmd/app", "services/billing/cmd/api"}},
+		{
+			name:     "include and exclude",
+			patterns: []string{"services/**", "!**/tools/**"},
+			expected: []string{"services/billing/cmd/api"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := glob.Select(tc.patterns, names); !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("expected %v, but got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestGlob(t *testing.T) {
+	root := t.TempDir()
+	for _, f := range []string{".git/config", "Makefile", "cmd/app/Dockerfile", "cmd/app/main.go", "config/app/a.yaml", "config/app/b.yaml"} {
+		if err := os.MkdirAll(filepath.Join(root, filepath.Dir(f)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(root, f), []byte(f), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := glob.Glob(root, []string{"cmd/app/Dockerfile", "config/app/*.yaml", "**/config"})
+	if err != nil {
+		t.Fatalf("failed to glob: %s", err)
+	}
+	if expected := []string{"cmd/app/Dockerfile", "config/app/a.yaml", "config/app/b.yaml"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, but got %v", expected, got)
+	}
+}
+
+func TestGlobDirs(t *testing.T) {
+	root := t.TempDir()
+	for _, f := range []string{"Makefile", "cmd/app/main.go", "cmd/app/internal/x.go", "config/app/a.yaml", "pkg/lib/lib.go"} {
+		if err := os.MkdirAll(filepath.Join(root, filepath.Dir(f)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(root, f), []byte(f), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, dirs, err := glob.GlobDirs(root, []string{"cmd/*/main.go", "config/**/*.yaml"})
+	if err != nil {
+		t.Fatalf("failed to glob: %s", err)
+	}
+	if expected := []string{"cmd/app/main.go", "config/app/a.yaml"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, but got %v", expected, got)
+	}
+
+	// Directories no pattern can match files under are not walked
+	walked := []string{}
+	for dir := range dirs {
+		walked = append(walked, dir)
+	}
+	slices.Sort(walked)
+	if expected := []string{".", "cmd", "cmd/app", "config", "config/app"}; !reflect.DeepEqual(walked, expected) {
+		t.Errorf("expected %v, but got %v", expected, walked)
+	}
+}
@@ -0,0 +1,106 @@
+package monogo
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/brunoluiz/monogo/glob"
+	"github.com/brunoluiz/monogo/graph"
+	"github.com/brunoluiz/monogo/walker"
+	"github.com/brunoluiz/monogo/walker/hook"
+	"github.com/samber/lo"
+	"golang.org/x/sync/errgroup"
+)
+
+// Graph returns the import graph of the module packages reached by the entrypoints and libraries. Without a
+// compare ref, the tree in the path is used as it is. Otherwise, the graph is built from the compare ref and
+// packages with files changed against the base ref are marked as changed.
+func (r *Detector) Graph(ctx context.Context) (graph.Graph, error) {
+	if r.CompareRef == "" {
+		w, err := walker.New(r.Path, r.Logger.WithGroup("walker:current"))
+		if err != nil {
+			return graph.Graph{}, err
+		}
+		return r.graphOn(ctx, w, r.Path, nil)
+	}
+
+	diff, err := r.Source.Diff(r.CompareRef, r.BaseRef)
+	if err != nil {
+		return graph.Graph{}, fmt.Errorf("failed to load diff: %w", err)
+	}
+	diff, _ = r.withoutIgnored(diff)
+
+	var g graph.Graph
+	err = r.Source.RunOnRef(r.CompareRef, func(dir string) error {
+		w, err := r.newWalker(dir, r.CompareRef, "walker:"+r.CompareRef)
+		if err != nil {
+			return err
+		}
+		g, err = r.graphOn(ctx, w, dir, diff.All())
+		return err
+	})
+	return g, err
+}
+
+// graphOn builds the graph from the walker tree in dir, marking the packages containing any of the changed files.
+// Deleted Go files mark the package in their directory.
+func (r *Detector) graphOn(ctx context.Context, w *walker.Walker, dir string, changes []string) (graph.Graph, error) {
+	targets, err := r.targets(ctx, w)
+	if err != nil {
+		return graph.Graph{}, fmt.Errorf("failed to get entrypoints: %w", err)
+	}
+
+	// Runs each entrypoint walker with go routines: you must test it with `-race` enabled
+	imports := map[string][]string{}
+	changed := []string{}
+	eg, egCtx := errgroup.WithContext(ctx)
+	rw := sync.RWMutex{}
+	for _, entry := range targets {
+		entry := entry
+		eg.Go(func() error {
+			graphHook := hook.NewImportGraph(false)
+			changesHook := hook.NewChangeDetector(absPaths(dir, changes))
+			if err := w.Walk(egCtx, entry, graphHook, changesHook); err != nil {
+				return err
+			}
+
+			// Write operations to shared memory below
+			rw.Lock()
+			defer rw.Unlock()
+			for pkg, deps := range graphHook.Imports() {
+				imports[pkg] = deps
+			}
+			changed = append(changed, lo.Values(changesHook.Matches())...)
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return graph.Graph{}, fmt.Errorf("failure while walking entrypoints: %w", err)
+	}
+
+	g := graph.New(w.Module(), imports)
+	changedIDs := lo.Map(changed, func(pkg string, _ int) string { return g.ID(pkg) })
+	for _, file := range changes {
+		if strings.HasSuffix(file, ".go") {
+			changedIDs = append(changedIDs, path.Dir(file))
+		}
+	}
+
+	entrypoints, _ := lo.Difference(targets, r.Libraries)
+	g.Mark(lo.Map(entrypoints, func(entry string, _ int) string { return nodeID(entry) }), func(n *graph.Node) {
+		n.Entrypoint = true
+	})
+	g.Mark(changedIDs, func(n *graph.Node) { n.Changed = true })
+	return g, nil
+}
+
+// nodeID returns the graph node ID of the entrypoint (eg: ./cmd/app -> cmd/app)
+func nodeID(entry string) string {
+	if id := glob.Clean(entry); id != "" {
+		return id
+	}
+	return "."
+}
@@ -0,0 +1,73 @@
+package graph
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// WriteDOT writes the graph in the Graphviz DOT language. Entrypoints are drawn in bold and changed
+// packages are filled.
+func WriteDOT(w io.Writer, g Graph) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %s {\n", strconv.Quote(g.Module))
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box];\n")
+	for _, n := range g.Nodes {
+		attrs := []string{}
+		switch {
+		case n.Entrypoint && n.Changed:
+			attrs = append(attrs, `style="bold,filled"`, fmt.Sprintf("fillcolor=%q", changedColor))
+		case n.Changed:
+			attrs = append(attrs, "style=filled", fmt.Sprintf("fillcolor=%q", changedColor))
+		case n.Entrypoint:
+			attrs = append(attrs, "style=bold")
+		}
+
+		fmt.Fprintf(&b, "  %s", strconv.Quote(n.ID))
+		if len(attrs) > 0 {
+			fmt.Fprintf(&b, " [%s]", strings.Join(attrs, ", "))
+		}
+		b.WriteString(";\n")
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&b, "  %s -> %s;\n", strconv.Quote(e.From), strconv.Quote(e.To))
+	}
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteMermaid writes the graph as a Mermaid flowchart, which can be embedded in markdown documents (eg: PR
+// summaries). Entrypoints have a thicker border and changed packages are filled.
+func WriteMermaid(w io.Writer, g Graph) error {
+	// Mermaid IDs can't contain slashes, so nodes are referred by index and labelled with their ID
+	index := map[string]string{}
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	for i, n := range g.Nodes {
+		index[n.ID] = fmt.Sprintf("n%d", i)
+		fmt.Fprintf(&b, "  %s[\"%s\"]\n", index[n.ID], strings.ReplaceAll(n.ID, `"`, "#quot;"))
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&b, "  %s --> %s\n", index[e.From], index[e.To])
+	}
+
+	b.WriteString("  classDef entrypoint stroke-width:3px\n")
+	fmt.Fprintf(&b, "  classDef changed fill:%s\n", changedColor)
+	for _, n := range g.Nodes {
+		if n.Entrypoint {
+			fmt.Fprintf(&b, "  class %s entrypoint\n", index[n.ID])
+		}
+		if n.Changed {
+			fmt.Fprintf(&b, "  class %s changed\n", index[n.ID])
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+const changedColor = "#ffb366"
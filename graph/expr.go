@@ -0,0 +1,249 @@
+package graph
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+// Query evaluates a query expression, returning the sorted IDs of the matching nodes. Expressions are
+// package sets combined with `intersect` (or `^`), `union` (or `+`) and `except` (or `-`), evaluated from
+// left to right unless grouped with parentheses. Sets are either packages (eg: ./pkg/shared, pkg/...)
+// or functions:
+//
+//   - deps(x): packages transitively imported by x
+//   - rdeps(x): packages transitively importing x
+//   - somepath(x, y): packages in the shortest import chain from x to y
+//   - allpaths(x, y): packages in any import chain from x to y
+//   - entrypoints(): main packages
+//   - changed(): packages marked as changed (eg: graphs built between two refs)
+//   - module(path): packages within the module path
+//
+// Example: `rdeps(./pkg/shared) intersect entrypoints()`
+func (g Graph) Query(expr string) ([]string, error) {
+	p := &parser{g: g, tokens: tokenize(expr)}
+	set, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q", p.tokens[p.pos])
+	}
+
+	out := lo.Keys(set)
+	slices.Sort(out)
+	return out, nil
+}
+
+type set map[string]bool
+
+type parser struct {
+	g      Graph
+	tokens []string
+	pos    int
+}
+
+var operators = map[string]func(a, b set) set{
+	"intersect": intersect, "^": intersect,
+	"union": union, "+": union,
+	"except": except, "-": except,
+}
+
+func (p *parser) expr() (set, error) {
+	out, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.pos < len(p.tokens) {
+		op, ok := operators[p.tokens[p.pos]]
+		if !ok {
+			return out, nil
+		}
+		p.pos++
+
+		next, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		out = op(out, next)
+	}
+	return out, nil
+}
+
+func (p *parser) term() (set, error) {
+	token, ok := p.next()
+	switch {
+	case !ok:
+		return nil, fmt.Errorf("unexpected end of query")
+	case token == "(":
+		out, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		return out, p.expect(")")
+	case token == ")" || token == ",":
+		return nil, fmt.Errorf("unexpected %q", token)
+	case p.peek() == "(":
+		p.pos++
+		return p.call(token)
+	default:
+		return p.packages(token)
+	}
+}
+
+// call evaluates the function, once its opening parenthesis is consumed
+func (p *parser) call(name string) (set, error) {
+	switch name {
+	case "deps", "rdeps":
+		arg, err := p.args(1)
+		if err != nil {
+			return nil, err
+		}
+		fn := lo.Ternary(name == "deps", p.g.Deps, p.g.RDeps)
+		out := set{}
+		for id := range arg[0] {
+			out = union(out, toSet(fn(id)))
+		}
+		return out, nil
+	case "somepath", "allpaths":
+		args, err := p.args(2)
+		if err != nil {
+			return nil, err
+		}
+		out := set{}
+		for _, from := range sortedIDs(args[0]) {
+			for _, to := range sortedIDs(args[1]) {
+				if name == "allpaths" {
+					out = union(out, toSet(lo.Flatten(p.g.AllPaths(from, to))))
+				} else if len(out) == 0 {
+					out = toSet(p.g.SomePath(from, to))
+				}
+			}
+		}
+		return out, nil
+	case "entrypoints", "changed":
+		if _, err := p.args(0); err != nil {
+			return nil, err
+		}
+		return p.nodes(func(n Node) bool { return lo.Ternary(name == "entrypoints", n.Entrypoint, n.Changed) }), nil
+	case "module":
+		module, ok := p.next()
+		if !ok || module == ")" {
+			return nil, fmt.Errorf("module() expects a module path")
+		}
+		return p.nodes(p.g.InModule(module)), p.expect(")")
+	default:
+		return nil, fmt.Errorf("unknown function %s", name)
+	}
+}
+
+// args evaluates n comma separated expressions, plus the closing parenthesis
+func (p *parser) args(n int) ([]set, error) {
+	out := []set{}
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+		arg, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, arg)
+	}
+	return out, p.expect(")")
+}
+
+// packages returns the node matching the package, or the ones within the directory for `/...` patterns
+func (p *parser) packages(pattern string) (set, error) {
+	if dir, ok := strings.CutSuffix(pattern, "..."); ok {
+		dir = strings.TrimSuffix(dir, "/")
+		return p.nodes(func(n Node) bool {
+			return p.g.InDir(dir)(n) || (n.External && p.g.InModule(dir)(n))
+		}), nil
+	}
+
+	id, ok := p.g.Lookup(pattern)
+	if !ok {
+		return nil, fmt.Errorf("package %s not found in the graph", pattern)
+	}
+	return set{id: true}, nil
+}
+
+func (p *parser) nodes(fn func(n Node) bool) set {
+	return toSet(lo.Map(p.g.Filter(fn).Nodes, func(n Node, _ int) string { return n.ID }))
+}
+
+func (p *parser) next() (string, bool) {
+	if p.pos >= len(p.tokens) {
+		return "", false
+	}
+	p.pos++
+	return p.tokens[p.pos-1], true
+}
+
+func (p *parser) peek() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *parser) expect(token string) error {
+	if next, ok := p.next(); !ok || next != token {
+		return fmt.Errorf("expected %q", token)
+	}
+	return nil
+}
+
+// tokenize splits the expression into words and the `(`, `)` and `,` symbols
+func tokenize(expr string) []string {
+	tokens := []string{}
+	word := strings.Builder{}
+	flush := func() {
+		if word.Len() > 0 {
+			tokens = append(tokens, word.String())
+			word.Reset()
+		}
+	}
+
+	for _, r := range expr {
+		switch {
+		case r == '(' || r == ')' || r == ',':
+			flush()
+			tokens = append(tokens, string(r))
+		case r == ' ' || r == '\t' || r == '\n':
+			flush()
+		default:
+			word.WriteRune(r)
+		}
+	}
+	flush()
+	return tokens
+}
+
+func toSet(ids []string) set {
+	return lo.SliceToMap(ids, func(id string) (string, bool) { return id, true })
+}
+
+func sortedIDs(s set) []string {
+	out := lo.Keys(s)
+	slices.Sort(out)
+	return out
+}
+
+func intersect(a, b set) set {
+	return lo.PickBy(a, func(id string, _ bool) bool { return b[id] })
+}
+
+func union(a, b set) set {
+	return lo.Assign(a, b)
+}
+
+func except(a, b set) set {
+	return lo.OmitBy(a, func(id string, _ bool) bool { return b[id] })
+}
@@ -0,0 +1,136 @@
+package graph
+
+import (
+	"cmp"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+// Graph contains the packages of a module as nodes and their imports as edges. Node IDs are slash separated
+// paths relative to the module (eg: cmd/app), where the module root package is `.`.
+type Graph struct {
+	Module string `json:"module"`
+	Nodes  []Node `json:"nodes"`
+	Edges  []Edge `json:"edges"`
+}
+
+type Node struct {
+	ID         string `json:"id"`
+	Entrypoint bool   `json:"entrypoint,omitempty"`
+	Changed    bool   `json:"changed,omitempty"`
+	// External is set for packages outside the module, where the ID is the package path
+	External bool `json:"external,omitempty"`
+}
+
+// Edge means the From package imports the To package
+type Edge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// New builds the graph from the imports of each package, by package path
+func New(module string, imports map[string][]string) Graph {
+	g := Graph{Module: module, Nodes: []Node{}, Edges: []Edge{}}
+	pkgs := map[string]bool{}
+	for pkg, deps := range imports {
+		pkgs[pkg] = true
+		for _, dep := range deps {
+			pkgs[dep] = true
+			g.Edges = append(g.Edges, Edge{From: g.ID(pkg), To: g.ID(dep)})
+		}
+	}
+
+	for pkg := range pkgs {
+		g.Nodes = append(g.Nodes, Node{ID: g.ID(pkg), External: !g.contains(pkg)})
+	}
+	return g.sorted()
+}
+
+// ID returns the node ID of the package path
+func (g Graph) ID(pkgPath string) string {
+	if pkgPath == g.Module {
+		return "."
+	}
+	if id, ok := strings.CutPrefix(pkgPath, g.Module+"/"); ok {
+		return id
+	}
+	return pkgPath
+}
+
+// PkgPath returns the package path of the node
+func (g Graph) PkgPath(n Node) string {
+	switch {
+	case n.External:
+		return n.ID
+	case n.ID == ".":
+		return g.Module
+	default:
+		return g.Module + "/" + n.ID
+	}
+}
+
+// Lookup returns the ID of the node matching the package, which can be set as a node ID, a path relative
+// to the module (eg: ./pkg/a) or a package path
+func (g Graph) Lookup(pkg string) (string, bool) {
+	for _, id := range []string{pkg, g.ID(pkg), path.Clean(pkg)} {
+		if g.has(id) {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+func (g Graph) contains(pkgPath string) bool {
+	return pkgPath == g.Module || strings.HasPrefix(pkgPath, g.Module+"/")
+}
+
+// Mark calls fn for each node with one of the IDs, so their attributes can be set
+func (g Graph) Mark(ids []string, fn func(n *Node)) {
+	for i := range g.Nodes {
+		if slices.Contains(ids, g.Nodes[i].ID) {
+			fn(&g.Nodes[i])
+		}
+	}
+}
+
+// Collapse merges the packages by directory, keeping up to depth path components of each ID (eg: with a depth
+// of 1, `pkg/a` and `pkg/b` become `pkg`). Merged nodes keep the attributes of any of their packages, and
+// imports within the same directory are dropped.
+func (g Graph) Collapse(depth int) Graph {
+	if depth <= 0 {
+		return g
+	}
+
+	collapse := func(id string) string {
+		parts := strings.Split(id, "/")
+		return strings.Join(parts[:min(depth, len(parts))], "/")
+	}
+
+	nodes := map[string]Node{}
+	for _, n := range g.Nodes {
+		merged := nodes[collapse(n.ID)]
+		merged.ID = collapse(n.ID)
+		merged.Entrypoint = merged.Entrypoint || n.Entrypoint
+		merged.Changed = merged.Changed || n.Changed
+		merged.External = n.External
+		nodes[merged.ID] = merged
+	}
+
+	edges := lo.FilterMap(g.Edges, func(e Edge, _ int) (Edge, bool) {
+		collapsed := Edge{From: collapse(e.From), To: collapse(e.To)}
+		return collapsed, collapsed.From != collapsed.To
+	})
+
+	return Graph{Module: g.Module, Nodes: lo.Values(nodes), Edges: lo.Uniq(edges)}.sorted()
+}
+
+func (g Graph) sorted() Graph {
+	slices.SortFunc(g.Nodes, func(a, b Node) int { return strings.Compare(a.ID, b.ID) })
+	slices.SortFunc(g.Edges, func(a, b Edge) int {
+		return cmp.Or(strings.Compare(a.From, b.From), strings.Compare(a.To, b.To))
+	})
+	return g
+}
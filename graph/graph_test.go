@@ -0,0 +1,114 @@
+package graph_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/brunoluiz/monogo/graph"
+	"github.com/stretchr/testify/require"
+)
+
+func testGraph() graph.Graph {
+	g := graph.New("example.com/project", map[string][]string{
+		"example.com/project/cmd/app":   {"example.com/project/pkg/a", "example.com/project/pkg/b"},
+		"example.com/project/cmd/other": {"example.com/project/pkg/b"},
+		"example.com/project/pkg/a":     {"example.com/project/pkg/b", "example.com/project"},
+		"example.com/project/pkg/b":     {},
+		"example.com/project":           {},
+	})
+	g.Mark([]string{"cmd/app", "cmd/other"}, func(n *graph.Node) { n.Entrypoint = true })
+	g.Mark([]string{"pkg/b"}, func(n *graph.Node) { n.Changed = true })
+	return g
+}
+
+func TestNew(t *testing.T) {
+	require.Equal(t, graph.Graph{
+		Module: "example.com/project",
+		Nodes: []graph.Node{
+			{ID: "."},
+			{ID: "cmd/app", Entrypoint: true},
+			{ID: "cmd/other", Entrypoint: true},
+			{ID: "pkg/a"},
+			{ID: "pkg/b", Changed: true},
+		},
+		Edges: []graph.Edge{
+			{From: "cmd/app", To: "pkg/a"},
+			{From: "cmd/app", To: "pkg/b"},
+			{From: "cmd/other", To: "pkg/b"},
+			{From: "pkg/a", To: "."},
+			{From: "pkg/a", To: "pkg/b"},
+		},
+	}, testGraph())
+}
+
+func TestGraph_Collapse(t *testing.T) {
+	testCases := []struct {
+		name     string
+		depth    int
+		expected graph.Graph
+	}{
+		{
+			name:     "disabled",
+			depth:    0,
+			expected: testGraph(),
+		},
+		{
+			name:  "top level directories",
+			depth: 1,
+			expected: graph.Graph{
+				Module: "example.com/project",
+				Nodes: []graph.Node{
+					{ID: "."},
+					{ID: "cmd", Entrypoint: true},
+					{ID: "pkg", Changed: true},
+				},
+				Edges: []graph.Edge{
+					{From: "cmd", To: "pkg"},
+					{From: "pkg", To: "."},
+				},
+			},
+		},
+		{
+			name:     "deeper than packages",
+			depth:    3,
+			expected: testGraph(),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, testGraph().Collapse(tc.depth))
+		})
+	}
+}
+
+func TestWriteDOT(t *testing.T) {
+	var b bytes.Buffer
+	require.NoError(t, graph.WriteDOT(&b, testGraph().Collapse(1)))
+	require.Equal(t, `digraph "example.com/project" {
+  rankdir=LR;
+  node [shape=box];
+  ".";
+  "cmd" [style=bold];
+  "pkg" [style=filled, fillcolor="#ffb366"];
+  "cmd" -> "pkg";
+  "pkg" -> ".";
+}
+`, b.String())
+}
+
+func TestWriteMermaid(t *testing.T) {
+	var b bytes.Buffer
+	require.NoError(t, graph.WriteMermaid(&b, testGraph().Collapse(1)))
+	require.Equal(t, `flowchart LR
+  n0["."]
+  n1["cmd"]
+  n2["pkg"]
+  n1 --> n2
+  n2 --> n0
+  classDef entrypoint stroke-width:3px
+  classDef changed fill:#ffb366
+  class n1 entrypoint
+  class n2 changed
+`, b.String())
+}
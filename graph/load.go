@@ -0,0 +1,34 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/brunoluiz/monogo/walker"
+	"github.com/brunoluiz/monogo/walker/hook"
+)
+
+// Load walks the packages matching the patterns (eg: cmd/app or pkg/...), relative to the walker base path,
+// and returns their import graph. All module packages are loaded if no pattern is set. Main packages are
+// marked as entrypoints. If external is set, packages outside the module are part of the graph too.
+func Load(ctx context.Context, w *walker.Walker, external bool, patterns ...string) (Graph, error) {
+	if len(patterns) == 0 {
+		patterns = []string{"..."}
+	}
+
+	graphHook := hook.NewImportGraph(external)
+	for _, pattern := range patterns {
+		if err := w.Walk(ctx, pattern, graphHook); err != nil {
+			return Graph{}, fmt.Errorf("failed to walk %s: %w", pattern, err)
+		}
+	}
+
+	mains, err := w.Discover(ctx)
+	if err != nil {
+		return Graph{}, err
+	}
+
+	g := New(w.Module(), graphHook.Imports())
+	g.Mark(mains, func(n *Node) { n.Entrypoint = true })
+	return g, nil
+}
@@ -0,0 +1,142 @@
+package graph
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+// Deps returns the IDs of the packages transitively imported by the node, sorted and excluding itself
+func (g Graph) Deps(id string) []string {
+	return g.reach(g.forward(), id)
+}
+
+// RDeps returns the IDs of the packages transitively importing the node, sorted and excluding itself
+func (g Graph) RDeps(id string) []string {
+	return g.reach(g.reverse(), id)
+}
+
+// SomePath returns the shortest import chain from one node to another, or nil if there is none
+func (g Graph) SomePath(from, to string) []string {
+	edges := g.forward()
+	parents := map[string]string{from: ""}
+	queue := []string{from}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if id == to {
+			chain := []string{}
+			for ; id != ""; id = parents[id] {
+				chain = append(chain, id)
+			}
+			slices.Reverse(chain)
+			return chain
+		}
+
+		for _, next := range edges[id] {
+			if _, ok := parents[next]; !ok {
+				parents[next] = id
+				queue = append(queue, next)
+			}
+		}
+	}
+	return nil
+}
+
+// AllPaths returns every import chain from one node to another, sorted
+func (g Graph) AllPaths(from, to string) [][]string {
+	edges := g.forward()
+	paths := [][]string{}
+
+	// Collapsed graphs might contain cycles, so nodes already in the chain are not visited again
+	var visit func(chain []string)
+	visit = func(chain []string) {
+		id := chain[len(chain)-1]
+		if id == to {
+			paths = append(paths, slices.Clone(chain))
+			return
+		}
+		for _, next := range edges[id] {
+			if !slices.Contains(chain, next) {
+				visit(append(chain, next))
+			}
+		}
+	}
+	if g.has(from) {
+		visit([]string{from})
+	}
+
+	slices.SortFunc(paths, slices.Compare)
+	return paths
+}
+
+// Filter returns the graph with the nodes kept by fn, plus the edges between them
+func (g Graph) Filter(fn func(n Node) bool) Graph {
+	nodes := lo.Filter(g.Nodes, func(n Node, _ int) bool { return fn(n) })
+	ids := lo.SliceToMap(nodes, func(n Node) (string, bool) { return n.ID, true })
+	edges := lo.Filter(g.Edges, func(e Edge, _ int) bool { return ids[e.From] && ids[e.To] })
+	return Graph{Module: g.Module, Nodes: nodes, Edges: edges}
+}
+
+// InDir matches the module packages within the directory, relative to the module (eg: pkg/storage)
+func (g Graph) InDir(dir string) func(n Node) bool {
+	dir = strings.TrimPrefix(strings.TrimPrefix(dir, "./"), "/")
+	return func(n Node) bool {
+		if n.External {
+			return false
+		}
+		return dir == "" || dir == "." || n.ID == dir || strings.HasPrefix(n.ID, strings.TrimSuffix(dir, "/")+"/")
+	}
+}
+
+// InModule matches the packages whose path is within the module path (eg: github.com/aws/aws-sdk-go-v2)
+func (g Graph) InModule(module string) func(n Node) bool {
+	return func(n Node) bool {
+		pkgPath := g.PkgPath(n)
+		return pkgPath == module || strings.HasPrefix(pkgPath, module+"/")
+	}
+}
+
+func (g Graph) has(id string) bool {
+	return slices.ContainsFunc(g.Nodes, func(n Node) bool { return n.ID == id })
+}
+
+// forward returns the imports of each node
+func (g Graph) forward() map[string][]string {
+	edges := map[string][]string{}
+	for _, e := range g.Edges {
+		edges[e.From] = append(edges[e.From], e.To)
+	}
+	return edges
+}
+
+// reverse returns the importers of each node
+func (g Graph) reverse() map[string][]string {
+	edges := map[string][]string{}
+	for _, e := range g.Edges {
+		edges[e.To] = append(edges[e.To], e.From)
+	}
+	return edges
+}
+
+// reach returns the sorted nodes reachable from id through the edges, excluding itself
+func (g Graph) reach(edges map[string][]string, id string) []string {
+	visited := map[string]bool{id: true}
+	queue := []string{id}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, next := range edges[current] {
+			if !visited[next] {
+				visited[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	delete(visited, id)
+	out := lo.Keys(visited)
+	slices.Sort(out)
+	return out
+}
@@ -0,0 +1,128 @@
+package graph_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/brunoluiz/monogo/graph"
+	"github.com/brunoluiz/monogo/walker"
+	"github.com/stretchr/testify/require"
+)
+
+func queryGraph() graph.Graph {
+	g := graph.New("example.com/project", map[string][]string{
+		"example.com/project/cmd/app":    {"example.com/project/pkg/a", "example.com/project/pkg/b"},
+		"example.com/project/cmd/other":  {"example.com/project/pkg/b"},
+		"example.com/project/pkg/a":      {"example.com/project/pkg/shared"},
+		"example.com/project/pkg/b":      {"example.com/project/pkg/shared", "github.com/pkg/errors"},
+		"example.com/project/pkg/shared": {"fmt"},
+		"example.com/project/pkg/unused": {},
+		"github.com/pkg/errors":          {"fmt"},
+		"fmt":                            {},
+	})
+	g.Mark([]string{"cmd/app", "cmd/other"}, func(n *graph.Node) { n.Entrypoint = true })
+	g.Mark([]string{"pkg/a"}, func(n *graph.Node) { n.Changed = true })
+	return g
+}
+
+func TestGraph_Deps(t *testing.T) {
+	g := queryGraph()
+	require.Equal(t, []string{"fmt", "github.com/pkg/errors", "pkg/a", "pkg/b", "pkg/shared"}, g.Deps("cmd/app"))
+	require.Equal(t, []string{"cmd/app", "cmd/other", "pkg/a", "pkg/b"}, g.RDeps("pkg/shared"))
+	require.Empty(t, g.RDeps("cmd/app"))
+	require.Empty(t, g.Deps("unknown"))
+}
+
+func TestGraph_Paths(t *testing.T) {
+	g := queryGraph()
+	require.Equal(t, []string{"cmd/app", "pkg/a", "pkg/shared"}, g.SomePath("cmd/app", "pkg/shared"))
+	require.Nil(t, g.SomePath("cmd/other", "pkg/a"))
+	require.Equal(t, [][]string{
+		{"cmd/app", "pkg/a", "pkg/shared", "fmt"},
+		{"cmd/app", "pkg/b", "github.com/pkg/errors", "fmt"},
+		{"cmd/app", "pkg/b", "pkg/shared", "fmt"},
+	}, g.AllPaths("cmd/app", "fmt"))
+	require.Empty(t, g.AllPaths("pkg/a", "cmd/app"))
+}
+
+func TestGraph_Filter(t *testing.T) {
+	g := queryGraph()
+
+	inPkg := g.Filter(g.InDir("./pkg"))
+	require.Equal(t, []graph.Node{{ID: "pkg/a", Changed: true}, {ID: "pkg/b"}, {ID: "pkg/shared"}, {ID: "pkg/unused"}}, inPkg.Nodes)
+	require.Equal(t, []graph.Edge{{From: "pkg/a", To: "pkg/shared"}, {From: "pkg/b", To: "pkg/shared"}}, inPkg.Edges)
+
+	external := g.Filter(g.InModule("github.com/pkg/errors"))
+	require.Equal(t, []graph.Node{{ID: "github.com/pkg/errors", External: true}}, external.Nodes)
+	require.Len(t, g.Filter(g.InModule("example.com/project")).Nodes, 6)
+}
+
+func TestGraph_Lookup(t *testing.T) {
+	g := queryGraph()
+	for _, pkg := range []string{"pkg/shared", "./pkg/shared", "example.com/project/pkg/shared"} {
+		id, ok := g.Lookup(pkg)
+		require.True(t, ok, pkg)
+		require.Equal(t, "pkg/shared", id)
+	}
+	_, ok := g.Lookup("pkg/missing")
+	require.False(t, ok)
+}
+
+func TestGraph_Query(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expr     string
+		expected []string
+		err      bool
+	}{
+		{name: "package", expr: "./pkg/shared", expected: []string{"pkg/shared"}},
+		{name: "directory", expr: "./pkg/...", expected: []string{"pkg/a", "pkg/b", "pkg/shared", "pkg/unused"}},
+		{name: "external directory", expr: "github.com/...", expected: []string{"github.com/pkg/errors"}},
+		{name: "rdeps intersect entrypoints", expr: "rdeps(./pkg/shared) intersect entrypoints()", expected: []string{"cmd/app", "cmd/other"}},
+		{name: "rdeps of changed", expr: "rdeps(changed()) ^ entrypoints()", expected: []string{"cmd/app"}},
+		{name: "deps except external", expr: "deps(cmd/other) except module(github.com/pkg/errors) - fmt", expected: []string{"pkg/b", "pkg/shared"}},
+		{name: "union", expr: "pkg/a + pkg/b union pkg/unused", expected: []string{"pkg/a", "pkg/b", "pkg/unused"}},
+		{name: "grouping", expr: "entrypoints() - (cmd/app + cmd/other)", expected: []string{}},
+		{name: "somepath", expr: "somepath(cmd/app, fmt)", expected: []string{"cmd/app", "fmt", "pkg/a", "pkg/shared"}},
+		{name: "allpaths", expr: "allpaths(entrypoints(), github.com/pkg/errors)", expected: []string{"cmd/app", "cmd/other", "github.com/pkg/errors", "pkg/b"}},
+		{name: "unknown package", expr: "rdeps(pkg/missing)", err: true},
+		{name: "unknown function", expr: "tests(pkg/a)", err: true},
+		{name: "missing argument", expr: "somepath(cmd/app)", err: true},
+		{name: "unclosed", expr: "rdeps(pkg/a", err: true},
+		{name: "trailing tokens", expr: "pkg/a pkg/b", err: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := queryGraph().Query(tc.expr)
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, res)
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	w, err := walker.New("../testdata/test-project", slog.Default())
+	require.NoError(t, err)
+
+	g, err := graph.Load(context.Background(), w, false)
+	require.NoError(t, err)
+	require.Equal(t, "test-project", g.Module)
+	require.Equal(t, []graph.Node{
+		{ID: "cmd/app1", Entrypoint: true},
+		{ID: "cmd/app2", Entrypoint: true},
+		{ID: "cmd/app3", Entrypoint: true},
+		{ID: "pkg/pkgA"},
+		{ID: "pkg/pkgB"},
+		{ID: "pkg/shared"},
+	}, g.Nodes)
+
+	external, err := graph.Load(context.Background(), w, true, "cmd/app1")
+	require.NoError(t, err)
+	require.Contains(t, external.Deps("cmd/app1"), "go.uber.org/zap")
+}
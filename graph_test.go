@@ -0,0 +1,60 @@
+package monogo_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/brunoluiz/monogo"
+	xgit "github.com/brunoluiz/monogo/git"
+	"github.com/brunoluiz/monogo/graph"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetector_Graph(t *testing.T) {
+	tmpDir, w, b := setupTestRepo(t)
+	commitFile(t, w, "pkg/pkgB/b.go", "package pkgB\n\nfunc B() string { return \"changed\" }\n")
+
+	g, err := xgit.New(xgit.WithPath(tmpDir))
+	require.NoError(t, err)
+
+	t.Run("current tree", func(t *testing.T) {
+		d := monogo.NewDetector([]string{"cmd/app1", "./cmd/app2"}, slog.Default(), nil,
+			monogo.WithPath(tmpDir),
+		)
+		res, err := d.Graph(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, graph.Graph{
+			Module: "test-project",
+			Nodes: []graph.Node{
+				{ID: "cmd/app1", Entrypoint: true},
+				{ID: "cmd/app2", Entrypoint: true},
+				{ID: "pkg/pkgA"},
+				{ID: "pkg/pkgB"},
+				{ID: "pkg/shared"},
+			},
+			Edges: []graph.Edge{
+				{From: "cmd/app1", To: "pkg/pkgA"},
+				{From: "cmd/app1", To: "pkg/shared"},
+				{From: "cmd/app2", To: "pkg/pkgB"},
+				{From: "cmd/app2", To: "pkg/shared"},
+			},
+		}, res)
+	})
+
+	t.Run("changes between refs", func(t *testing.T) {
+		d := monogo.NewDetector([]string{"cmd/app1", "cmd/app2"}, slog.Default(), g,
+			monogo.WithPath(tmpDir),
+			monogo.WithCompareRef(string(b)),
+		)
+		res, err := d.Graph(context.Background())
+		require.NoError(t, err)
+		changed := []string{}
+		for _, n := range res.Nodes {
+			if n.Changed {
+				changed = append(changed, n.ID)
+			}
+		}
+		require.Equal(t, []string{"pkg/pkgB"}, changed)
+	})
+}
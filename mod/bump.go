@@ -0,0 +1,51 @@
+package mod
+
+import (
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// BumpType classifies a module version change
+type BumpType string
+
+const (
+	BumpMajor     BumpType = "major"
+	BumpMinor     BumpType = "minor"
+	BumpPatch     BumpType = "patch"
+	BumpPseudo    BumpType = "pseudo-version"
+	BumpDowngrade BumpType = "downgrade"
+	BumpAdded     BumpType = "added"
+	BumpRemoved   BumpType = "removed"
+)
+
+// Bump holds the old and new versions of a module. Old is empty for added
+// modules, while New is empty for removed ones.
+type Bump struct {
+	Path string
+	Old  string
+	New  string
+	Type BumpType
+}
+
+func NewBump(path, oldVersion, newVersion string) Bump {
+	return Bump{Path: path, Old: oldVersion, New: newVersion, Type: classify(oldVersion, newVersion)}
+}
+
+func classify(oldVersion, newVersion string) BumpType {
+	switch {
+	case oldVersion == "":
+		return BumpAdded
+	case newVersion == "":
+		return BumpRemoved
+	case semver.Compare(newVersion, oldVersion) < 0:
+		return BumpDowngrade
+	case module.IsPseudoVersion(oldVersion) || module.IsPseudoVersion(newVersion):
+		return BumpPseudo
+	case semver.Major(oldVersion) != semver.Major(newVersion):
+		return BumpMajor
+	case semver.MajorMinor(oldVersion) != semver.MajorMinor(newVersion):
+		return BumpMinor
+	default:
+		return BumpPatch
+	}
+}
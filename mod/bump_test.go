@@ -0,0 +1,40 @@
+package mod_test
+
+import (
+	"testing"
+
+	"github.com/brunoluiz/monogo/mod"
+)
+
+func TestNewBump(t *testing.T) {
+	testCases := []struct {
+		name     string
+		old      string
+		new      string
+		expected mod.BumpType
+	}{
+		{name: "major", old: "v1.2.3", new: "v2.0.0", expected: mod.BumpMajor},
+		{name: "major incompatible", old: "v2.3.0+incompatible", new: "v3.0.0+incompatible", expected: mod.BumpMajor},
+		{name: "minor", old: "v1.2.3", new: "v1.3.0", expected: mod.BumpMinor},
+		{name: "patch", old: "v1.2.3", new: "v1.2.4", expected: mod.BumpPatch},
+		{name: "pre-release to release", old: "v1.2.0-rc.1", new: "v1.2.0", expected: mod.BumpPatch},
+		{name: "pseudo-version", old: "v0.0.0-20240101000000-abcdefabcdef", new: "v0.0.0-20240201000000-abcdefabcdef", expected: mod.BumpPseudo},
+		{name: "release to pseudo-version", old: "v1.2.3", new: "v1.2.4-0.20240201000000-abcdefabcdef", expected: mod.BumpPseudo},
+		{name: "downgrade", old: "v1.3.0", new: "v1.2.9", expected: mod.BumpDowngrade},
+		{name: "pseudo-version downgrade", old: "v0.0.0-20240201000000-abcdefabcdef", new: "v0.0.0-20240101000000-abcdefabcdef", expected: mod.BumpDowngrade},
+		{name: "added", old: "", new: "v1.0.0", expected: mod.BumpAdded},
+		{name: "removed", old: "v1.0.0", new: "", expected: mod.BumpRemoved},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b := mod.NewBump("example.com/a", tc.old, tc.new)
+			if b.Type != tc.expected {
+				t.Errorf("expected bump to be %s, but got %s", tc.expected, b.Type)
+			}
+			if b.Old != tc.old || b.New != tc.new {
+				t.Errorf("unexpected versions: %+v", b)
+			}
+		})
+	}
+}
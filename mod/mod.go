@@ -1,9 +1,13 @@
 package mod
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/samber/lo"
 	"golang.org/x/mod/modfile"
@@ -17,6 +21,8 @@ const (
 	ChangePackages
 	ChangeGolang
 	ChangeGolangToolchain
+	ChangeGodebug
+	ChangeTools
 )
 
 type WithOpt func(c *options)
@@ -49,32 +55,161 @@ func Get(opts ...WithOpt) (string, *modfile.File, error) {
 	return modfile.ModulePath(data), m, nil
 }
 
+// GetSums returns the go.sum module hashes, indexed by `path@version` (and `path@version/go.mod`).
+// A missing go.sum is not an error, as modules without dependencies do not have one.
+func GetSums(opts ...WithOpt) (map[string]string, error) {
+	c := options{path: "go.mod"}
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	sums := map[string]string{}
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(c.path), "go.sum"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return sums, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error to open go sum file: %w", err)
+	}
+
+	for i, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("error to parse go sum file: malformed line %d", i+1)
+		}
+		sums[fields[0]+"@"+fields[1]] = fields[2]
+	}
+
+	return sums, nil
+}
+
 type ChangedPackages struct {
 	Added   []string
 	Deleted []string
 	Changed []string
 	None    []string
+	// Bumps contains version details for added, deleted and changed packages, indexed by module path
+	Bumps map[string]Bump
 }
 
 func (c ChangedPackages) All() []string {
 	return lo.Uniq(append(append(c.Added, c.Deleted...), c.Changed...))
 }
 
+// VersionChange holds the go directive or toolchain versions found on both sides of a diff
+type VersionChange struct {
+	Old string
+	New string
+}
+
+func (v VersionChange) Changed() bool {
+	return v.Old != v.New
+}
+
 type Output struct {
-	Type     ChangeType
+	Type      ChangeType
+	Golang    VersionChange
+	Toolchain VersionChange
+	// Godebug contains the godebug keys which were added, removed or had their value changed
+	Godebug  []string
 	Packages ChangedPackages
+	// Tools contains the tool directives which were added or removed
+	Tools ChangedPackages
 }
 
+// Diff compares two go modules. The Type is set based on the most relevant change found
+// (go > toolchain > godebug > packages > tools), but all changes are always populated in the output.
 func Diff(leftMod, rightMod *modfile.File) Output {
-	if lo.FromPtr(leftMod.Go).Version != lo.FromPtr(rightMod.Go).Version {
-		return Output{Type: ChangeGolang}
+	out := Output{
+		Type:      ChangeNone,
+		Golang:    VersionChange{Old: GoVersion(leftMod), New: GoVersion(rightMod)},
+		Toolchain: VersionChange{Old: toolchainName(leftMod), New: toolchainName(rightMod)},
+		Godebug:   diffGodebug(leftMod, rightMod),
+		Packages:  diffPackages(leftMod, rightMod),
+		Tools:     diffTools(leftMod, rightMod),
+	}
+
+	switch {
+	case out.Golang.Changed():
+		out.Type = ChangeGolang
+	case out.Toolchain.Changed():
+		out.Type = ChangeGolangToolchain
+	case len(out.Godebug) > 0:
+		out.Type = ChangeGodebug
+	case len(out.Packages.All()) > 0:
+		out.Type = ChangePackages
+	case len(out.Tools.All()) > 0:
+		out.Type = ChangeTools
 	}
 
-	if lo.FromPtr(leftMod.Toolchain).Name != lo.FromPtr(rightMod.Toolchain).Name {
-		return Output{Type: ChangeGolangToolchain}
+	return out
+}
+
+func diffGodebug(leftMod, rightMod *modfile.File) []string {
+	leftKeys := lo.SliceToMap(leftMod.Godebug, func(item *modfile.Godebug) (string, string) {
+		return item.Key, item.Value
+	})
+	rightKeys := lo.SliceToMap(rightMod.Godebug, func(item *modfile.Godebug) (string, string) {
+		return item.Key, item.Value
+	})
+
+	changed := []string{}
+	for _, key := range lo.Union(lo.Keys(leftKeys), lo.Keys(rightKeys)) {
+		leftValue, leftFound := leftKeys[key]
+		rightValue, rightFound := rightKeys[key]
+		if leftFound != rightFound || leftValue != rightValue {
+			changed = append(changed, key)
+		}
+	}
+
+	slices.Sort(changed)
+	return changed
+}
+
+func diffTools(leftMod, rightMod *modfile.File) ChangedPackages {
+	toolPath := func(item *modfile.Tool, _ int) string { return item.Path }
+	left := lo.Map(leftMod.Tool, toolPath)
+	right := lo.Map(rightMod.Tool, toolPath)
+
+	added, deleted := lo.Difference(right, left)
+	return ChangedPackages{
+		Added:   added,
+		Deleted: deleted,
+		None:    lo.Intersect(left, right),
 	}
+}
+
+// GoVersion returns the go directive version
+func GoVersion(m *modfile.File) string {
+	return lo.FromPtr(m.Go).Version
+}
+
+// toolchainName returns the declared toolchain name, if any
+func toolchainName(m *modfile.File) string {
+	if m.Toolchain == nil {
+		return ""
+	}
+	return m.Toolchain.Name
+}
 
+// ToolchainVersion returns the toolchain name, falling back to the go directive
+// version as that is the toolchain used when none is declared
+func ToolchainVersion(m *modfile.File) string {
+	if m.Toolchain != nil && m.Toolchain.Name != "" {
+		return m.Toolchain.Name
+	}
+	if v := GoVersion(m); v != "" {
+		return "go" + v
+	}
+	return ""
+}
+
+func diffPackages(leftMod, rightMod *modfile.File) ChangedPackages {
 	left, right, changed, none := []string{}, []string{}, []string{}, []string{}
+	bumps := map[string]Bump{}
 	leftPkgs := lo.SliceToMap(leftMod.Require, func(item *modfile.Require) (string, *modfile.Require) {
 		return item.Mod.Path, item
 	})
@@ -86,12 +221,14 @@ func Diff(leftMod, rightMod *modfile.File) Output {
 		rightPkg, found := rightPkgs[leftKey]
 		if !found {
 			left = append(left, leftPkg.Mod.Path)
+			bumps[leftPkg.Mod.Path] = NewBump(leftPkg.Mod.Path, leftPkg.Mod.Version, "")
 			continue
 		}
 
 		// NOTE: I think it only needs to be done once
 		if leftPkg.Mod.Version != rightPkg.Mod.Version {
 			changed = append(changed, leftPkg.Mod.Path)
+			bumps[leftPkg.Mod.Path] = NewBump(leftPkg.Mod.Path, leftPkg.Mod.Version, rightPkg.Mod.Version)
 			continue
 		}
 
@@ -102,18 +239,16 @@ func Diff(leftMod, rightMod *modfile.File) Output {
 		_, found := leftPkgs[rightKey]
 		if !found {
 			right = append(right, rightPkg.Mod.Path)
+			bumps[rightPkg.Mod.Path] = NewBump(rightPkg.Mod.Path, "", rightPkg.Mod.Version)
 			continue
 		}
 	}
 
-	if len(left) > 0 || len(right) > 0 || len(changed) > 0 {
-		return Output{Type: ChangePackages, Packages: ChangedPackages{
-			Added:   right,
-			Deleted: left,
-			None:    lo.Uniq(none),
-			Changed: lo.Uniq(changed),
-		}}
+	return ChangedPackages{
+		Added:   right,
+		Deleted: left,
+		None:    lo.Uniq(none),
+		Changed: lo.Uniq(changed),
+		Bumps:   bumps,
 	}
-
-	return Output{Type: ChangeNone}
 }
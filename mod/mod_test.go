@@ -1,6 +1,8 @@
 package mod_test
 
 import (
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
@@ -8,6 +10,25 @@ import (
 	"golang.org/x/mod/modfile"
 )
 
+func normaliseSlice(s *[]string) {
+	if *s == nil {
+		*s = []string{}
+	}
+}
+
+func normaliseOutput(o *mod.Output) {
+	for _, pkgs := range []*mod.ChangedPackages{&o.Packages, &o.Tools} {
+		normaliseSlice(&pkgs.Added)
+		normaliseSlice(&pkgs.Deleted)
+		normaliseSlice(&pkgs.Changed)
+		normaliseSlice(&pkgs.None)
+		if pkgs.Bumps == nil {
+			pkgs.Bumps = map[string]mod.Bump{}
+		}
+	}
+	normaliseSlice(&o.Godebug)
+}
+
 // nolint:funlen
 func TestDiff(t *testing.T) {
 	testCases := []struct {
@@ -32,7 +53,11 @@ require (
     "example.com/a" v1.0.0
 )
 `,
-			expected: mod.Output{Type: mod.ChangeNone},
+			expected: mod.Output{
+				Type:     mod.ChangeNone,
+				Golang:   mod.VersionChange{Old: "1.21", New: "1.21"},
+				Packages: mod.ChangedPackages{None: []string{"example.com/a"}},
+			},
 		},
 		{
 			name: "go version change",
@@ -44,7 +69,10 @@ go 1.20
 module my/project
 go 1.21
 `,
-			expected: mod.Output{Type: mod.ChangeGolang},
+			expected: mod.Output{
+				Type:   mod.ChangeGolang,
+				Golang: mod.VersionChange{Old: "1.20", New: "1.21"},
+			},
 		},
 		{
 			name: "toolchain version change",
@@ -58,7 +86,11 @@ module my/project
 go 1.21
 toolchain go1.22.0
 `,
-			expected: mod.Output{Type: mod.ChangeGolangToolchain},
+			expected: mod.Output{
+				Type:      mod.ChangeGolangToolchain,
+				Golang:    mod.VersionChange{Old: "1.21", New: "1.21"},
+				Toolchain: mod.VersionChange{Old: "go1.21.0", New: "go1.22.0"},
+			},
 		},
 		{
 			name: "package added",
@@ -78,10 +110,14 @@ require (
 )
 `,
 			expected: mod.Output{
-				Type: mod.ChangePackages,
+				Type:   mod.ChangePackages,
+				Golang: mod.VersionChange{Old: "1.21", New: "1.21"},
 				Packages: mod.ChangedPackages{
 					Added: []string{"example.com/b"},
 					None:  []string{"example.com/a"},
+					Bumps: map[string]mod.Bump{
+						"example.com/b": {Path: "example.com/b", New: "v1.0.0", Type: mod.BumpAdded},
+					},
 				},
 			},
 		},
@@ -103,10 +139,14 @@ require (
 )
 `,
 			expected: mod.Output{
-				Type: mod.ChangePackages,
+				Type:   mod.ChangePackages,
+				Golang: mod.VersionChange{Old: "1.21", New: "1.21"},
 				Packages: mod.ChangedPackages{
 					Deleted: []string{"example.com/b"},
 					None:    []string{"example.com/a"},
+					Bumps: map[string]mod.Bump{
+						"example.com/b": {Path: "example.com/b", Old: "v1.0.0", Type: mod.BumpRemoved},
+					},
 				},
 			},
 		},
@@ -127,9 +167,13 @@ require (
 )
 `,
 			expected: mod.Output{
-				Type: mod.ChangePackages,
+				Type:   mod.ChangePackages,
+				Golang: mod.VersionChange{Old: "1.21", New: "1.21"},
 				Packages: mod.ChangedPackages{
 					Changed: []string{"example.com/a"},
+					Bumps: map[string]mod.Bump{
+						"example.com/a": {Path: "example.com/a", Old: "v1.0.0", New: "v1.1.0", Type: mod.BumpMinor},
+					},
 				},
 			},
 		},
@@ -152,11 +196,69 @@ require (
 )
 `,
 			expected: mod.Output{
-				Type: mod.ChangePackages,
+				Type:   mod.ChangePackages,
+				Golang: mod.VersionChange{Old: "1.21", New: "1.21"},
 				Packages: mod.ChangedPackages{
 					Added:   []string{"example.com/c"},
 					Deleted: []string{"example.com/b"},
 					Changed: []string{"example.com/a"},
+					Bumps: map[string]mod.Bump{
+						"example.com/a": {Path: "example.com/a", Old: "v1.0.0", New: "v1.1.0", Type: mod.BumpMinor},
+						"example.com/b": {Path: "example.com/b", Old: "v1.0.0", Type: mod.BumpRemoved},
+						"example.com/c": {Path: "example.com/c", New: "v1.0.0", Type: mod.BumpAdded},
+					},
+				},
+			},
+		},
+		{
+			name: "godebug changes",
+			left: `
+module my/project
+go 1.21
+godebug (
+    panicnil=1
+    asynctimerchan=1
+)
+`,
+			right: `
+module my/project
+go 1.21
+godebug (
+    panicnil=0
+    httpmuxgo121=1
+)
+`,
+			expected: mod.Output{
+				Type:    mod.ChangeGodebug,
+				Golang:  mod.VersionChange{Old: "1.21", New: "1.21"},
+				Godebug: []string{"asynctimerchan", "httpmuxgo121", "panicnil"},
+			},
+		},
+		{
+			name: "tool changes",
+			left: `
+module my/project
+go 1.24
+tool (
+    golang.org/x/tools/cmd/stringer
+    example.com/tool/a
+)
+`,
+			right: `
+module my/project
+go 1.24
+tool (
+    golang.org/x/tools/cmd/stringer
+    example.com/tool/b
+)
+`,
+			expected: mod.Output{
+				Type:   mod.ChangeTools,
+				Golang: mod.VersionChange{Old: "1.24", New: "1.24"},
+				Tools: mod.ChangedPackages{
+					Added:   []string{"example.com/tool/b"},
+					Deleted: []string{"example.com/tool/a"},
+					None:    []string{"golang.org/x/tools/cmd/stringer"},
 				},
 			},
 		},
@@ -177,30 +279,8 @@ require (
 			output := mod.Diff(leftMod, rightMod)
 
 			// Normalise slices for comparison
-			if output.Packages.Added == nil {
-				output.Packages.Added = []string{}
-			}
-			if output.Packages.Deleted == nil {
-				output.Packages.Deleted = []string{}
-			}
-			if output.Packages.Changed == nil {
-				output.Packages.Changed = []string{}
-			}
-			if output.Packages.None == nil {
-				output.Packages.None = []string{}
-			}
-			if tc.expected.Packages.Added == nil {
-				tc.expected.Packages.Added = []string{}
-			}
-			if tc.expected.Packages.Deleted == nil {
-				tc.expected.Packages.Deleted = []string{}
-			}
-			if tc.expected.Packages.Changed == nil {
-				tc.expected.Packages.Changed = []string{}
-			}
-			if tc.expected.Packages.None == nil {
-				tc.expected.Packages.None = []string{}
-			}
+			normaliseOutput(&output)
+			normaliseOutput(&tc.expected)
 
 			if !reflect.DeepEqual(output, tc.expected) {
 				t.Errorf("unexpected output, got %+v, want %+v", output, tc.expected)
@@ -208,3 +288,30 @@ require (
 		})
 	}
 }
+
+func TestGetSums(t *testing.T) {
+	dir := t.TempDir()
+	sum := "go.uber.org/zap v1.27.0 h1:aJMhYGrd5QSmlpLMr2MftRKl7t8J8PTZPA732ud/XR8=\n" +
+		"go.uber.org/zap v1.27.0/go.mod h1:GB2qFLM7cTU87MWRP2mPIjqfIDnGu+VIO4V/SdhGo2E=\n"
+	if err := os.WriteFile(filepath.Join(dir, "go.sum"), []byte(sum), 0o600); err != nil {
+		t.Fatalf("failed to write go.sum: %s", err)
+	}
+
+	sums, err := mod.GetSums(mod.WithModDir(dir))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := map[string]string{
+		"go.uber.org/zap@v1.27.0":        "h1:aJMhYGrd5QSmlpLMr2MftRKl7t8J8PTZPA732ud/XR8=",
+		"go.uber.org/zap@v1.27.0/go.mod": "h1:GB2qFLM7cTU87MWRP2mPIjqfIDnGu+VIO4V/SdhGo2E=",
+	}
+	if !reflect.DeepEqual(sums, expected) {
+		t.Errorf("unexpected sums, got %+v, want %+v", sums, expected)
+	}
+
+	sums, err = mod.GetSums(mod.WithModDir(t.TempDir()))
+	if err != nil || len(sums) != 0 {
+		t.Errorf("expected no sums and no error, got %+v and %v", sums, err)
+	}
+}
@@ -0,0 +1,83 @@
+package mod
+
+import (
+	"fmt"
+	"go/version"
+	"strings"
+)
+
+// Policy defines which go directive or toolchain changes are relevant
+type Policy string
+
+const (
+	// PolicyAll considers any version change
+	PolicyAll Policy = "all"
+	// PolicyNone ignores all version changes
+	PolicyNone Policy = "none"
+	// PolicyMinorOnly only considers language version changes (eg: 1.23 -> 1.24),
+	// ignoring patches and release candidates of the same language version
+	PolicyMinorOnly Policy = "minor-only"
+	// PolicyPatchIgnored ignores patch releases (eg: 1.23.1 -> 1.23.2), but still
+	// considers release candidates (eg: 1.24rc1 -> 1.24.0)
+	PolicyPatchIgnored Policy = "patch-ignored"
+)
+
+// Policies lists all supported policies
+var Policies = []Policy{PolicyAll, PolicyNone, PolicyMinorOnly, PolicyPatchIgnored}
+
+func ParsePolicy(s string) (Policy, error) {
+	for _, p := range Policies {
+		if string(p) == s {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("unknown version policy %q: must be one of %v", s, Policies)
+}
+
+// Triggers returns true if the version change is relevant under the policy
+func (p Policy) Triggers(c VersionChange) bool {
+	if !c.Changed() {
+		return false
+	}
+
+	switch p {
+	case PolicyNone:
+		return false
+	case PolicyMinorOnly:
+		return langVersion(c.Old) != langVersion(c.New)
+	case PolicyPatchIgnored:
+		return withoutPatch(c.Old) != withoutPatch(c.New)
+	default:
+		return true
+	}
+}
+
+// normalise prefixes go directive versions (1.23) so they can be used in go/version
+func normalise(v string) string {
+	if strings.HasPrefix(v, "go") {
+		return v
+	}
+	return "go" + v
+}
+
+func langVersion(v string) string {
+	if lang := version.Lang(normalise(v)); lang != "" {
+		return lang
+	}
+	return v
+}
+
+func withoutPatch(v string) string {
+	lang := version.Lang(normalise(v))
+	if lang == "" {
+		return v
+	}
+
+	// Release candidates and betas are kept as is (go1.24rc1), while
+	// releases have their patch stripped (go1.23.1 -> go1.23)
+	rest := strings.TrimPrefix(normalise(v), lang)
+	if rest == "" || strings.HasPrefix(rest, ".") {
+		return lang
+	}
+	return normalise(v)
+}
@@ -0,0 +1,49 @@
+package mod_test
+
+import (
+	"testing"
+
+	"github.com/brunoluiz/monogo/mod"
+)
+
+func TestPolicy_Triggers(t *testing.T) {
+	testCases := []struct {
+		name     string
+		policy   mod.Policy
+		change   mod.VersionChange
+		expected bool
+	}{
+		{name: "all: no change", policy: mod.PolicyAll, change: mod.VersionChange{Old: "1.23", New: "1.23"}, expected: false},
+		{name: "all: patch bump", policy: mod.PolicyAll, change: mod.VersionChange{Old: "1.23.1", New: "1.23.2"}, expected: true},
+		{name: "none: minor bump", policy: mod.PolicyNone, change: mod.VersionChange{Old: "1.23", New: "1.24"}, expected: false},
+		{name: "minor-only: patch bump", policy: mod.PolicyMinorOnly, change: mod.VersionChange{Old: "1.23.1", New: "1.23.2"}, expected: false},
+		{name: "minor-only: release candidate", policy: mod.PolicyMinorOnly, change: mod.VersionChange{Old: "1.24rc1", New: "1.24.0"}, expected: false},
+		{name: "minor-only: minor bump", policy: mod.PolicyMinorOnly, change: mod.VersionChange{Old: "1.23.4", New: "1.24.0"}, expected: true},
+		{name: "minor-only: toolchain minor bump", policy: mod.PolicyMinorOnly, change: mod.VersionChange{Old: "go1.23.4", New: "go1.24.0"}, expected: true},
+		{name: "patch-ignored: patch bump", policy: mod.PolicyPatchIgnored, change: mod.VersionChange{Old: "go1.23.1", New: "go1.23.2"}, expected: false},
+		{name: "patch-ignored: release candidate", policy: mod.PolicyPatchIgnored, change: mod.VersionChange{Old: "1.24rc1", New: "1.24.0"}, expected: true},
+		{name: "patch-ignored: minor bump", policy: mod.PolicyPatchIgnored, change: mod.VersionChange{Old: "1.23", New: "1.24"}, expected: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.policy.Triggers(tc.change); got != tc.expected {
+				t.Errorf("expected triggers to be %v, but got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestParsePolicy(t *testing.T) {
+	p, err := mod.ParsePolicy("minor-only")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if p != mod.PolicyMinorOnly {
+		t.Errorf("expected %s, got %s", mod.PolicyMinorOnly, p)
+	}
+
+	if _, err := mod.ParsePolicy("sometimes"); err == nil {
+		t.Error("expected error for unknown policy")
+	}
+}
@@ -0,0 +1,137 @@
+package monogo
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/brunoluiz/monogo/mod"
+	"github.com/brunoluiz/monogo/walker"
+	"github.com/brunoluiz/monogo/walker/hook"
+	"github.com/samber/lo"
+	"golang.org/x/mod/modfile"
+)
+
+// AllPackagesEntrypoint is the pseudo entrypoint used to walk all module packages and their tests
+const AllPackagesEntrypoint = "..."
+
+type ModUnusedOpts struct {
+	// Tests includes test dependencies, from the entrypoints and all module packages
+	Tests bool
+	// Tools includes dependencies from go.mod tool directives
+	Tools bool
+}
+
+type ModUnusedRes struct {
+	Hash string `json:"hash"`
+	Ref  string `json:"ref"`
+	// Unused contains requirements not reached by any entrypoint
+	Unused []ModRequirementRes `json:"unused"`
+	// SingleUser contains requirements reached by exactly one entrypoint, candidates for moving
+	SingleUser []ModRequirementRes `json:"single_user"`
+}
+
+type ModRequirementRes struct {
+	Path     string   `json:"path"`
+	Version  string   `json:"version"`
+	Indirect bool     `json:"indirect"`
+	Users    []string `json:"users"`
+}
+
+// ModUnused walks all entrypoints at the given ref and reports go.mod requirements
+// not reached by any of them, as well as the ones only reached by a single entrypoint.
+// Tools are reported as `tool <path>` users.
+func (r *Detector) ModUnused(ctx context.Context, ref string, opts ModUnusedOpts) (ModUnusedRes, error) {
+	hash, refName, err := r.Git.Ref(ref)
+	if err != nil {
+		return ModUnusedRes{}, fmt.Errorf("failed to get ref: %w", err)
+	}
+
+	var modFile *modfile.File
+	usersByModule := map[string][]string{}
+	rw := sync.RWMutex{}
+	use := func(user string, deps *hook.Deps) {
+		rw.Lock()
+		defer rw.Unlock()
+		for _, m := range deps.Modules() {
+			usersByModule[m.Path] = append(usersByModule[m.Path], user)
+		}
+	}
+
+	// When including tests, all module packages are walked so tests from non-entrypoint packages are considered
+	var extra []string
+	if opts.Tests {
+		extra = []string{AllPackagesEntrypoint}
+	}
+	prepare := func(ctx context.Context, w *walker.Walker) error {
+		module, m, err := mod.Get(mod.WithModDir(r.Path))
+		if err != nil {
+			return err
+		}
+		modFile = m
+		if !opts.Tools {
+			return nil
+		}
+
+		for _, tool := range modFile.Tool {
+			toolHook := hook.NewDeps()
+			if err := r.walkTool(ctx, w, module, tool.Path, toolHook); err != nil {
+				return err
+			}
+			use("tool "+tool.Path, toolHook)
+		}
+		return nil
+	}
+
+	_, err = r.walkEntrypoints(ctx, ref, extra, prepare, func(ctx context.Context, w *walker.Walker, entry string) error {
+		depsHook := hook.NewDeps()
+		if err := w.Walk(ctx, entry, depsHook); err != nil {
+			return err
+		}
+		use(entry, depsHook)
+		return nil
+	}, walker.WithTests(opts.Tests))
+	if err != nil {
+		return ModUnusedRes{}, fmt.Errorf("failure while walking entrypoints: %w", err)
+	}
+
+	res := ModUnusedRes{Hash: hash, Ref: refName, Unused: []ModRequirementRes{}, SingleUser: []ModRequirementRes{}}
+	for _, req := range modFile.Require {
+		users := lo.Uniq(usersByModule[req.Mod.Path])
+		slices.Sort(users)
+
+		item := ModRequirementRes{Path: req.Mod.Path, Version: req.Mod.Version, Indirect: req.Indirect, Users: users}
+		switch len(users) {
+		case 0:
+			res.Unused = append(res.Unused, item)
+		case 1:
+			res.SingleUser = append(res.SingleUser, item)
+		}
+	}
+
+	sortRequirements := func(a, b ModRequirementRes) int { return strings.Compare(a.Path, b.Path) }
+	slices.SortFunc(res.Unused, sortRequirements)
+	slices.SortFunc(res.SingleUser, sortRequirements)
+	return res, nil
+}
+
+// walkTool collects the dependencies of a tool, which might be part of the module or external to it
+func (r *Detector) walkTool(ctx context.Context, w *walker.Walker, module, tool string, deps *hook.Deps) error {
+	if local, ok := strings.CutPrefix(tool, module+"/"); ok {
+		return w.Walk(ctx, local, deps)
+	}
+
+	pkgs, err := w.Load(ctx, tool)
+	if err != nil {
+		return fmt.Errorf("failed to load tool %s: %w", tool, err)
+	}
+	for _, p := range pkgs {
+		if len(p.Errors) != 0 {
+			return fmt.Errorf("tool %s contains errors: %+v", tool, p.Errors)
+		}
+		deps.Visit(p)
+	}
+	return nil
+}
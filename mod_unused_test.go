@@ -0,0 +1,56 @@
+package monogo_test
+
+import (
+	"context"
+	"log/slog"
+	"path/filepath"
+	"testing"
+
+	"github.com/brunoluiz/monogo"
+	xgit "github.com/brunoluiz/monogo/git"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetector_ModUnused(t *testing.T) {
+	tmpDir, w, b := setupTestRepo(t)
+
+	// yaml.v3 is only used by tests, while multierr is only reached by app1 and app2 through shared
+	commitFileReplace(t, w, "go.mod", "require go.uber.org/zap v1.27.0", "require (\n\tgo.uber.org/zap v1.27.0\n\tgopkg.in/yaml.v3 v3.0.1\n)")
+	commitFile(t, w, filepath.Join("pkg", "pkgB", "b_test.go"), `package pkgB
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestB(t *testing.T) {
+	_, _ = yaml.Marshal(B())
+}
+`)
+
+	g, err := xgit.New(xgit.WithPath(tmpDir))
+	require.NoError(t, err)
+	d := monogo.NewDetector([]string{"cmd/app1", "cmd/app2"}, slog.Default(), g,
+		monogo.WithPath(tmpDir),
+	)
+
+	requirementPath := func(item monogo.ModRequirementRes, _ int) string { return item.Path }
+
+	t.Run("without tests", func(t *testing.T) {
+		res, err := d.ModUnused(context.Background(), string(b), monogo.ModUnusedOpts{})
+		require.NoError(t, err)
+		require.Equal(t, []string{"gopkg.in/yaml.v3"}, lo.Map(res.Unused, requirementPath))
+		require.Empty(t, res.SingleUser)
+	})
+
+	t.Run("with tests", func(t *testing.T) {
+		res, err := d.ModUnused(context.Background(), string(b), monogo.ModUnusedOpts{Tests: true})
+		require.NoError(t, err)
+		require.Empty(t, res.Unused)
+		require.Equal(t, []monogo.ModRequirementRes{
+			{Path: "gopkg.in/yaml.v3", Version: "v3.0.1", Users: []string{monogo.AllPackagesEntrypoint}},
+		}, res.SingleUser)
+	})
+}
@@ -0,0 +1,252 @@
+package patch
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// DevNull is the path used by unified diffs for the missing side of created and deleted files
+const DevNull = "/dev/null"
+
+// File contains the changes to a single file. Paths are relative to the repository, stripping the first
+// path component (eg: `a/` and `b/` in git diffs), like `patch -p1` does.
+type File struct {
+	// OldPath is empty for created files
+	OldPath string
+	// NewPath is empty for deleted files
+	NewPath string
+	// Binary is set for binary changes, which have no hunks
+	Binary bool
+	Hunks  []Hunk
+}
+
+// Created reports whether the file only exists after the patch
+func (f File) Created() bool { return f.OldPath == "" }
+
+// Deleted reports whether the file only exists before the patch
+func (f File) Deleted() bool { return f.NewPath == "" }
+
+// Renamed reports whether the file path changed
+func (f File) Renamed() bool { return !f.Created() && !f.Deleted() && f.OldPath != f.NewPath }
+
+type Hunk struct {
+	OldStart, OldLines int
+	NewStart, NewLines int
+	Lines              []Line
+}
+
+// Line is a hunk line, where Op is ' ' for context, '-' for removed and '+' for added lines
+type Line struct {
+	Op   byte
+	Text string
+	// NoNewline is set for the last line of a file without a trailing newline
+	NoNewline bool
+}
+
+var hunkHeader = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// Parse reads a unified diff, such as the output of `git diff` or `git format-patch`. Anything which is not
+// part of a file diff (eg: email headers and commit messages) is skipped.
+func Parse(r io.Reader) ([]File, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, strings.TrimSuffix(scanner.Text(), "\r"))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read patch: %w", err)
+	}
+
+	files := []File{}
+	var current *File
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			files = append(files, File{})
+			current = &files[len(files)-1]
+			if oldPath, newPath, ok := strings.Cut(strings.TrimPrefix(line, "diff --git "), " "); ok {
+				current.OldPath, current.NewPath = stripPrefix(oldPath), stripPrefix(newPath)
+			}
+		case current != nil && strings.HasPrefix(line, "new file mode"):
+			current.OldPath = ""
+		case current != nil && strings.HasPrefix(line, "deleted file mode"):
+			current.NewPath = ""
+		case current != nil && strings.HasPrefix(line, "rename from "):
+			current.OldPath = strings.TrimPrefix(line, "rename from ")
+		case current != nil && strings.HasPrefix(line, "rename to "):
+			current.NewPath = strings.TrimPrefix(line, "rename to ")
+		case current != nil && (strings.HasPrefix(line, "Binary files ") || line == "GIT binary patch"):
+			current.Binary = true
+		case strings.HasPrefix(line, "--- ") && i+1 < len(lines) && strings.HasPrefix(lines[i+1], "+++ "):
+			// Plain unified diffs have no `diff --git` header, so each file starts with its paths
+			if current == nil || len(current.Hunks) > 0 {
+				files = append(files, File{})
+				current = &files[len(files)-1]
+			}
+			current.OldPath = headerPath(strings.TrimPrefix(line, "--- "))
+			current.NewPath = headerPath(strings.TrimPrefix(lines[i+1], "+++ "))
+			i++
+		case current != nil && strings.HasPrefix(line, "@@ "):
+			hunk, consumed, err := parseHunk(lines[i:])
+			if err != nil {
+				return nil, fmt.Errorf("invalid hunk for %s: %w", current.path(), err)
+			}
+			current.Hunks = append(current.Hunks, hunk)
+			i += consumed - 1
+		}
+	}
+
+	for _, f := range files {
+		if f.OldPath == "" && f.NewPath == "" {
+			return nil, fmt.Errorf("invalid patch: file without paths")
+		}
+	}
+	return files, nil
+}
+
+func (f File) path() string {
+	if f.NewPath != "" {
+		return f.NewPath
+	}
+	return f.OldPath
+}
+
+// parseHunk parses the hunk starting at the first line, returning the number of lines consumed
+func parseHunk(lines []string) (Hunk, int, error) {
+	m := hunkHeader.FindStringSubmatch(lines[0])
+	if m == nil {
+		return Hunk{}, 0, fmt.Errorf("malformed header %q", lines[0])
+	}
+
+	hunk := Hunk{OldStart: atoi(m[1], 0), OldLines: atoi(m[2], 1), NewStart: atoi(m[3], 0), NewLines: atoi(m[4], 1)}
+	oldLeft, newLeft := hunk.OldLines, hunk.NewLines
+	i := 1
+	for ; i < len(lines) && (oldLeft > 0 || newLeft > 0 || strings.HasPrefix(lines[i], `\`)); i++ {
+		line := lines[i]
+		if strings.HasPrefix(line, `\`) {
+			if len(hunk.Lines) > 0 {
+				hunk.Lines[len(hunk.Lines)-1].NoNewline = true
+			}
+			continue
+		}
+
+		// Some tools strip the trailing space of empty context lines
+		op, text := byte(' '), ""
+		if line != "" {
+			op, text = line[0], line[1:]
+		}
+		switch op {
+		case ' ':
+			oldLeft--
+			newLeft--
+		case '-':
+			oldLeft--
+		case '+':
+			newLeft--
+		default:
+			return Hunk{}, 0, fmt.Errorf("unexpected line %q", line)
+		}
+		hunk.Lines = append(hunk.Lines, Line{Op: op, Text: text})
+	}
+
+	if oldLeft != 0 || newLeft != 0 {
+		return Hunk{}, 0, fmt.Errorf("truncated hunk %q", lines[0])
+	}
+	return hunk, i, nil
+}
+
+func atoi(s string, fallback int) int {
+	if s == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return fallback
+	}
+	return n
+}
+
+// headerPath returns the path of a `---` or `+++` line, without timestamps, or empty for /dev/null
+func headerPath(header string) string {
+	path, _, _ := strings.Cut(header, "\t")
+	path = strings.TrimSpace(path)
+	if path == DevNull {
+		return ""
+	}
+	return stripPrefix(path)
+}
+
+// stripPrefix removes the first path component, such as `a/` or `b/`
+func stripPrefix(path string) string {
+	if _, rest, ok := strings.Cut(path, "/"); ok {
+		return rest
+	}
+	return path
+}
+
+// Reverse reverts the file changes from the content after the patch, returning the content before it.
+// Created files have no content before the patch, while deleted files are rebuilt from their hunks.
+func (f File) Reverse(content string) (string, error) {
+	lines, eofNewline := splitLines(content)
+	offset := 0
+	for _, hunk := range f.Hunks {
+		var before, after []string
+		for _, line := range hunk.Lines {
+			if line.Op != '+' {
+				before = append(before, line.Text)
+			}
+			if line.Op != '-' {
+				after = append(after, line.Text)
+			}
+		}
+
+		// Hunks without lines on the new side refer to the line before them
+		start := hunk.NewStart - 1 + offset
+		if hunk.NewLines == 0 {
+			start++
+		}
+		if start < 0 || start+len(after) > len(lines) || !slices.Equal(lines[start:start+len(after)], after) {
+			return "", fmt.Errorf("hunk %d,%d does not apply to %s", hunk.NewStart, hunk.NewLines, f.path())
+		}
+
+		lines = append(lines[:start], append(before, lines[start+len(after):]...)...)
+		offset += len(before) - len(after)
+		eofNewline = hunk.eofNewline(eofNewline)
+	}
+
+	if len(lines) == 0 {
+		return "", nil
+	}
+	out := strings.Join(lines, "\n")
+	if eofNewline {
+		out += "\n"
+	}
+	return out, nil
+}
+
+// eofNewline returns whether the content before the hunk ends with a newline, given the content after it
+func (h Hunk) eofNewline(after bool) bool {
+	// Markers on context or removed lines apply to the old side, and on added lines to the new side only
+	if slices.ContainsFunc(h.Lines, func(l Line) bool { return l.NoNewline && l.Op != '+' }) {
+		return false
+	}
+	if slices.ContainsFunc(h.Lines, func(l Line) bool { return l.NoNewline && l.Op == '+' }) {
+		return true
+	}
+	return after
+}
+
+func splitLines(content string) ([]string, bool) {
+	if content == "" {
+		return []string{}, true
+	}
+	eofNewline := strings.HasSuffix(content, "\n")
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n"), eofNewline
+}
@@ -0,0 +1,209 @@
+package patch_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/brunoluiz/monogo/git"
+	"github.com/brunoluiz/monogo/patch"
+	"github.com/stretchr/testify/require"
+)
+
+const gitPatch = `From 1234 Mon Sep 17 00:00:00 2001
+Subject: [PATCH] change things
+
+--- a/ignored.txt is not a file header, as there is no +++ line
+---
+ pkg/a.go | 2 +-
+
+diff --git a/pkg/a.go b/pkg/a.go
+index 1111111..2222222 100644
+--- a/pkg/a.go
++++ b/pkg/a.go
+@@ -1,3 +1,3 @@
+ package pkg
+ 
+-const A = "a"
++const A = "b"
+diff --git a/pkg/new.go b/pkg/new.go
+new file mode 100644
+index 0000000..3333333
+--- /dev/null
++++ b/pkg/new.go
+@@ -0,0 +1 @@
++package pkg
+diff --git a/pkg/old.go b/pkg/old.go
+deleted file mode 100644
+index 4444444..0000000
+--- a/pkg/old.go
++++ /dev/null
+@@ -1,2 +0,0 @@
+-package pkg
+-// old
+diff --git a/cmd/foo/main.go b/cmd/bar/main.go
+similarity index 100%
+rename from cmd/foo/main.go
+rename to cmd/bar/main.go
+diff --git a/assets/logo.png b/assets/logo.png
+index 5555555..6666666 100644
+Binary files a/assets/logo.png and b/assets/logo.png differ
+`
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []patch.File
+		err      string
+	}{
+		{
+			name:  "should parse git patches",
+			input: gitPatch,
+			expected: []patch.File{
+				{OldPath: "pkg/a.go", NewPath: "pkg/a.go", Hunks: []patch.Hunk{{
+					OldStart: 1, OldLines: 3, NewStart: 1, NewLines: 3,
+					Lines: []patch.Line{{Op: ' ', Text: "package pkg"}, {Op: ' '}, {Op: '-', Text: `const A = "a"`}, {Op: '+', Text: `const A = "b"`}},
+				}}},
+				{NewPath: "pkg/new.go", Hunks: []patch.Hunk{{
+					OldStart: 0, OldLines: 0, NewStart: 1, NewLines: 1,
+					Lines: []patch.Line{{Op: '+', Text: "package pkg"}},
+				}}},
+				{OldPath: "pkg/old.go", Hunks: []patch.Hunk{{
+					OldStart: 1, OldLines: 2, NewStart: 0, NewLines: 0,
+					Lines: []patch.Line{{Op: '-', Text: "package pkg"}, {Op: '-', Text: "// old"}},
+				}}},
+				{OldPath: "cmd/foo/main.go", NewPath: "cmd/bar/main.go"},
+				{OldPath: "assets/logo.png", NewPath: "assets/logo.png", Binary: true},
+			},
+		},
+		{
+			name:  "should parse plain unified diffs",
+			input: "--- release-1/go.mod\t2024-01-01 10:00:00\n+++ release-2/go.mod\t2024-02-01 10:00:00\n@@ -1 +1 @@\n-go 1.22\n\\ No newline at end of file\n+go 1.23\n",
+			expected: []patch.File{
+				{OldPath: "go.mod", NewPath: "go.mod", Hunks: []patch.Hunk{{
+					OldStart: 1, OldLines: 1, NewStart: 1, NewLines: 1,
+					Lines: []patch.Line{{Op: '-', Text: "go 1.22", NoNewline: true}, {Op: '+', Text: "go 1.23"}},
+				}}},
+			},
+		},
+		{
+			name:  "should reject truncated hunks",
+			input: "--- a/go.mod\n+++ b/go.mod\n@@ -1,2 +1,2 @@\n-go 1.22\n",
+			err:   "invalid hunk for go.mod: truncated hunk",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := patch.Parse(strings.NewReader(tt.input))
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, files)
+		})
+	}
+}
+
+func TestFile_Reverse(t *testing.T) {
+	tests := []struct {
+		name     string
+		patch    string
+		content  string
+		expected string
+		err      string
+	}{
+		{
+			name:     "should revert multiple hunks",
+			patch:    "--- a/f\n+++ b/f\n@@ -1,2 +1,3 @@\n a\n+x\n b\n@@ -5,2 +6,1 @@\n e\n-f\n",
+			content:  "a\nx\nb\nc\nd\ne\n",
+			expected: "a\nb\nc\nd\ne\nf\n",
+		},
+		{
+			name:     "should revert missing newlines",
+			patch:    "--- a/f\n+++ b/f\n@@ -1 +1 @@\n-a\n\\ No newline at end of file\n+b\n",
+			content:  "b\n",
+			expected: "a",
+		},
+		{
+			name:     "should rebuild deleted files",
+			patch:    "--- a/f\n+++ /dev/null\n@@ -1,2 +0,0 @@\n-a\n-b\n",
+			content:  "",
+			expected: "a\nb\n",
+		},
+		{
+			name:    "should reject hunks not matching the content",
+			patch:   "--- a/f\n+++ b/f\n@@ -1 +1 @@\n-a\n+b\n",
+			content: "c\n",
+			err:     "hunk 1,1 does not apply to f",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := patch.Parse(strings.NewReader(tt.patch))
+			require.NoError(t, err)
+			require.Len(t, files, 1)
+
+			content, err := files[0].Reverse(tt.content)
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, content)
+		})
+	}
+}
+
+func TestSource(t *testing.T) {
+	dir := t.TempDir()
+	write := func(path, content string) {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, path)), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, path), []byte(content), 0o600))
+	}
+	// The tree with the patch already applied
+	write("pkg/a.go", "package pkg\n\nconst A = \"b\"\n")
+	write("pkg/new.go", "package pkg\n")
+	write("cmd/bar/main.go", "package main\n")
+	write("assets/logo.png", "png")
+	write(".git/HEAD", "ref: refs/heads/main\n")
+
+	s, err := patch.NewSource(dir, strings.NewReader(gitPatch))
+	require.NoError(t, err)
+
+	diff, err := s.Diff(patch.CompareRef, patch.BaseRef)
+	require.NoError(t, err)
+	require.Equal(t, git.DiffResult{
+		Created: []string{"cmd/bar/main.go", "pkg/new.go"},
+		Updated: []string{"assets/logo.png", "pkg/a.go"},
+		Deleted: []string{"cmd/foo/main.go", "pkg/old.go"},
+		Renamed: map[string]string{"cmd/bar/main.go": "cmd/foo/main.go"},
+	}, diff)
+
+	err = s.RunOnRef(patch.BaseRef, func(base string) error {
+		read := func(path string) string {
+			data, err := os.ReadFile(filepath.Join(base, path))
+			require.NoError(t, err)
+			return string(data)
+		}
+		require.Equal(t, "package pkg\n\nconst A = \"a\"\n", read("pkg/a.go"))
+		require.Equal(t, "package pkg\n// old\n", read("pkg/old.go"))
+		require.Equal(t, "package main\n", read("cmd/foo/main.go"))
+		require.Equal(t, "png", read("assets/logo.png"))
+		require.NoFileExists(t, filepath.Join(base, "pkg/new.go"))
+		require.NoFileExists(t, filepath.Join(base, "cmd/bar/main.go"))
+		require.NoDirExists(t, filepath.Join(base, ".git"))
+		return nil
+	})
+	require.NoError(t, err)
+
+	err = s.RunOnRef(patch.CompareRef, func(compare string) error {
+		require.Equal(t, dir, compare)
+		return nil
+	})
+	require.NoError(t, err)
+}
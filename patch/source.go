@@ -0,0 +1,187 @@
+package patch
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/brunoluiz/monogo/git"
+)
+
+const (
+	// BaseRef is the tree before the patch, rebuilt by reverting it from the current tree
+	BaseRef = "patch:base"
+	// CompareRef is the current tree, where the patch is already applied
+	CompareRef = "patch:compare"
+)
+
+// Source provides the trees before and after a patch, so changes can be detected when the base ref is not
+// available as a git object (eg: mailing-list patches). The patch must be applied to the tree in path.
+type Source struct {
+	path  string
+	hash  string
+	files []File
+}
+
+func NewSource(path string, r io.Reader) (*Source, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read patch: %w", err)
+	}
+
+	files, err := Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(data)
+	return &Source{path: path, hash: hex.EncodeToString(sum[:]), files: files}, nil
+}
+
+// Ref returns the patch checksum as the hash of both refs
+func (s *Source) Ref(ref string) (string, string, error) {
+	if ref != BaseRef && ref != CompareRef {
+		return "", "", fmt.Errorf("unknown patch ref %s: must be %s or %s", ref, BaseRef, CompareRef)
+	}
+	return s.hash, ref, nil
+}
+
+// Diff returns the files changed by the patch. Renamed files are also listed as created and deleted.
+func (s *Source) Diff(fromRef, compareRef string) (git.DiffResult, error) {
+	if fromRef != CompareRef || compareRef != BaseRef {
+		return git.DiffResult{}, fmt.Errorf("patch diffs are only available from %s to %s", CompareRef, BaseRef)
+	}
+
+	result := git.DiffResult{Created: []string{}, Updated: []string{}, Deleted: []string{}, Renamed: map[string]string{}}
+	for _, f := range s.files {
+		switch {
+		case f.Created():
+			result.Created = append(result.Created, f.NewPath)
+		case f.Deleted():
+			result.Deleted = append(result.Deleted, f.OldPath)
+		case f.Renamed():
+			result.Created = append(result.Created, f.NewPath)
+			result.Deleted = append(result.Deleted, f.OldPath)
+			result.Renamed[f.NewPath] = f.OldPath
+		default:
+			result.Updated = append(result.Updated, f.NewPath)
+		}
+	}
+
+	slices.Sort(result.Created)
+	slices.Sort(result.Updated)
+	slices.Sort(result.Deleted)
+	return result, nil
+}
+
+// RunOnRef runs cb on the current tree for the compare ref. For the base ref, the tree is copied to a
+// temporary directory, where the patch is reverted.
+func (s *Source) RunOnRef(ref string, cb func(dir string) error) error {
+	switch ref {
+	case CompareRef:
+		return cb(s.path)
+	case BaseRef:
+	default:
+		return fmt.Errorf("unknown patch ref %s: must be %s or %s", ref, BaseRef, CompareRef)
+	}
+
+	dir, err := os.MkdirTemp("", "monogo-patch-")
+	if err != nil {
+		return fmt.Errorf("failed to create base tree dir: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := copyTree(s.path, dir); err != nil {
+		return fmt.Errorf("failed to copy tree: %w", err)
+	}
+	for _, f := range s.files {
+		if err := revert(dir, f); err != nil {
+			return err
+		}
+	}
+
+	return cb(dir)
+}
+
+// revert reverts the file changes within dir
+func revert(dir string, f File) error {
+	content := []byte{}
+	if !f.Deleted() {
+		path := filepath.Join(dir, filepath.FromSlash(f.NewPath))
+		current, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s, is the patch applied?: %w", f.NewPath, err)
+		}
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", f.NewPath, err)
+		}
+		if f.Created() {
+			return nil
+		}
+		content = current
+	}
+
+	// Binary changes can't be reverted, so the current content is kept
+	if !f.Binary {
+		before, err := f.Reverse(string(content))
+		if err != nil {
+			return err
+		}
+		content = []byte(before)
+	}
+
+	target := filepath.Join(dir, filepath.FromSlash(f.OldPath))
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return fmt.Errorf("failed to create dir for %s: %w", f.OldPath, err)
+	}
+	if err := os.WriteFile(target, content, 0o644); err != nil { // nolint:gosec
+		return fmt.Errorf("failed to write %s: %w", f.OldPath, err)
+	}
+	return nil
+}
+
+// copyTree copies the files from src to dst, skipping the .git directory
+func copyTree(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		switch {
+		case d.IsDir() && d.Name() == ".git":
+			return filepath.SkipDir
+		case d.IsDir():
+			return os.MkdirAll(target, 0o755)
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case d.Type().IsRegular():
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			return os.WriteFile(target, data, info.Mode().Perm())
+		default:
+			return nil
+		}
+	})
+}
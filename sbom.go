@@ -0,0 +1,85 @@
+package monogo
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sync"
+
+	"github.com/brunoluiz/monogo/glob"
+	"github.com/brunoluiz/monogo/mod"
+	"github.com/brunoluiz/monogo/sbom"
+	"github.com/brunoluiz/monogo/vuln"
+	"github.com/brunoluiz/monogo/walker"
+	"github.com/brunoluiz/monogo/walker/hook"
+	"github.com/samber/lo"
+	"golang.org/x/tools/go/packages"
+)
+
+// SBOM returns a document per entrypoint, listing only the modules linked into it at the given ref.
+// Versions and hashes come from go.mod and go.sum, so nothing needs to be built.
+func (r *Detector) SBOM(ctx context.Context, ref string) ([]sbom.Document, error) {
+	hash, _, err := r.Git.Ref(ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ref: %w", err)
+	}
+
+	created, err := r.Git.CommitTime(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	var module, toolchain string
+	var sums map[string]string
+	prepare := func(_ context.Context, _ *walker.Walker) error {
+		name, m, err := mod.Get(mod.WithModDir(r.Path))
+		if err != nil {
+			return err
+		}
+		module, toolchain = name, mod.ToolchainVersion(m)
+
+		sums, err = mod.GetSums(mod.WithModDir(r.Path))
+		return err
+	}
+
+	docs := map[string]sbom.Document{}
+	rw := sync.RWMutex{}
+	entrypoints, err := r.walkEntrypoints(ctx, ref, nil, prepare, func(ctx context.Context, w *walker.Walker, entry string) error {
+		depsHook := hook.NewDeps()
+		if err := w.Walk(ctx, entry, depsHook); err != nil {
+			return err
+		}
+
+		components := lo.Map(depsHook.Modules(), func(m *packages.Module, _ int) sbom.Component {
+			sumKey := m.Path + "@" + m.Version
+			if m.Replace != nil && m.Replace.Version != "" {
+				sumKey = m.Replace.Path + "@" + m.Replace.Version
+			}
+			return sbom.Component{Path: m.Path, Version: hook.ModuleVersion(m), Hash: sums[sumKey]}
+		})
+
+		// The standard library is linked as well, with the version from the toolchain
+		if lo.SomeBy(lo.Values(depsHook.Packages()), hook.IsStd) {
+			components = append(components, sbom.Component{Path: vuln.ModuleStdlib, Version: vuln.GoVersion(toolchain)})
+		}
+
+		// Write operations to shared memory below
+		rw.Lock()
+		defer rw.Unlock()
+		docs[entry] = sbom.Document{
+			Entrypoint: entry,
+			Name:       path.Join(module, glob.Clean(entry)),
+			Revision:   hash,
+			Created:    created,
+			Components: components,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failure while generating sbom: %w", err)
+	}
+
+	return lo.Map(entrypoints, func(entry string, _ int) sbom.Document {
+		return docs[entry]
+	}), nil
+}
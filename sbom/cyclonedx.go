@@ -0,0 +1,86 @@
+package sbom
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type cdxBOM struct {
+	BOMFormat    string          `json:"bomFormat"`
+	SpecVersion  string          `json:"specVersion"`
+	Version      int             `json:"version"`
+	Metadata     cdxMetadata     `json:"metadata"`
+	Components   []cdxComponent  `json:"components"`
+	Dependencies []cdxDependency `json:"dependencies"`
+}
+
+type cdxMetadata struct {
+	Timestamp string       `json:"timestamp"`
+	Tools     cdxTools     `json:"tools"`
+	Component cdxComponent `json:"component"`
+}
+
+type cdxTools struct {
+	Components []cdxComponent `json:"components"`
+}
+
+type cdxComponent struct {
+	Type       string        `json:"type"`
+	BOMRef     string        `json:"bom-ref,omitempty"`
+	Name       string        `json:"name"`
+	Version    string        `json:"version,omitempty"`
+	PURL       string        `json:"purl,omitempty"`
+	Properties []cdxProperty `json:"properties,omitempty"`
+}
+
+type cdxProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type cdxDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn"`
+}
+
+// CycloneDX encodes the document as CycloneDX 1.5 JSON
+func CycloneDX(d Document) ([]byte, error) {
+	app := cdxComponent{
+		Type:    "application",
+		BOMRef:  purl(d.Name, ""),
+		Name:    d.Name,
+		Version: d.Revision,
+		PURL:    purl(d.Name, ""),
+	}
+
+	bom := cdxBOM{
+		BOMFormat:   "CycloneDX",
+		SpecVersion: "1.5",
+		Version:     1,
+		Metadata: cdxMetadata{
+			Timestamp: d.Created.UTC().Format(time.RFC3339),
+			Tools:     cdxTools{Components: []cdxComponent{{Type: "application", Name: toolName}}},
+			Component: app,
+		},
+		Components:   []cdxComponent{},
+		Dependencies: []cdxDependency{{Ref: app.BOMRef, DependsOn: []string{}}},
+	}
+
+	for _, c := range d.Components {
+		component := cdxComponent{
+			Type:    "library",
+			BOMRef:  c.PURL(),
+			Name:    c.Path,
+			Version: c.Version,
+			PURL:    c.PURL(),
+		}
+		if c.Hash != "" {
+			component.Properties = []cdxProperty{{Name: HashProperty, Value: c.Hash}}
+		}
+
+		bom.Components = append(bom.Components, component)
+		bom.Dependencies[0].DependsOn = append(bom.Dependencies[0].DependsOn, component.BOMRef)
+	}
+
+	return json.MarshalIndent(bom, "", "  ")
+}
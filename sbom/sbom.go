@@ -0,0 +1,73 @@
+package sbom
+
+import (
+	"fmt"
+	"time"
+)
+
+type Format string
+
+const (
+	FormatCycloneDX Format = "cyclonedx"
+	FormatSPDX      Format = "spdx"
+)
+
+const toolName = "monogo"
+
+// HashProperty is the annotation name carrying the go.sum h1 hash of a component
+const HashProperty = "go.sum:h1"
+
+// Document describes the modules linked into a single entrypoint
+type Document struct {
+	// Entrypoint is the entrypoint the document was generated for, as given or discovered (eg: cmd/app)
+	Entrypoint string
+	// Name is the entrypoint package path (eg: github.com/org/repo/cmd/app)
+	Name string
+	// Revision is the commit hash the document was generated from
+	Revision string
+	// Created is used as the document creation time, usually the commit time, so documents are reproducible
+	Created    time.Time
+	Components []Component
+}
+
+// Component is a module linked into the entrypoint
+type Component struct {
+	Path    string
+	Version string
+	// Hash is the go.sum hash (h1:base64). It is a dirhash of the module tree rather than a
+	// digest of a single artifact, so it is only ever emitted as a go.sum:h1 annotation
+	Hash string
+}
+
+// PURL returns the package URL (https://github.com/package-url/purl-spec) for the component
+func (c Component) PURL() string {
+	return purl(c.Path, c.Version)
+}
+
+func purl(path, version string) string {
+	p := "pkg:golang/" + path
+	if version != "" {
+		p += "@" + version
+	}
+	return p
+}
+
+// Encode encodes the document in the given format
+func Encode(d Document, format Format) ([]byte, error) {
+	switch format {
+	case FormatCycloneDX:
+		return CycloneDX(d)
+	case FormatSPDX:
+		return SPDX(d)
+	default:
+		return nil, fmt.Errorf("unknown sbom format: %s", format)
+	}
+}
+
+// Extension returns the conventional file extension for the format
+func Extension(format Format) string {
+	if format == FormatSPDX {
+		return ".spdx.json"
+	}
+	return ".cdx.json"
+}
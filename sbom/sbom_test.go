@@ -0,0 +1,76 @@
+package sbom_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/brunoluiz/monogo/sbom"
+	"github.com/stretchr/testify/require"
+)
+
+var doc = sbom.Document{
+	Name:     "example.com/project/cmd/app",
+	Revision: "18c61ae928daff98272ed3413a05738803718fb4",
+	Created:  time.Date(2025, 9, 3, 18, 37, 58, 0, time.UTC),
+	Components: []sbom.Component{
+		{Path: "go.uber.org/zap", Version: "v1.27.0", Hash: "h1:aJMhYGrd5QSmlpLMr2MftRKl7t8J8PTZPA732ud/XR8="},
+		{Path: "example.com/local", Version: ""},
+	},
+}
+
+func TestComponent(t *testing.T) {
+	require.Equal(t, "pkg:golang/go.uber.org/zap@v1.27.0", doc.Components[0].PURL())
+	require.Equal(t, "pkg:golang/example.com/local", doc.Components[1].PURL())
+}
+
+func TestCycloneDX(t *testing.T) {
+	data, err := sbom.Encode(doc, sbom.FormatCycloneDX)
+	require.NoError(t, err)
+
+	out := map[string]any{}
+	require.NoError(t, json.Unmarshal(data, &out))
+	require.Equal(t, "CycloneDX", out["bomFormat"])
+	require.Equal(t, "2025-09-03T18:37:58Z", out["metadata"].(map[string]any)["timestamp"])
+	require.Equal(t, doc.Name, out["metadata"].(map[string]any)["component"].(map[string]any)["name"])
+
+	components := out["components"].([]any)
+	require.Len(t, components, 2)
+	zap := components[0].(map[string]any)
+	require.Equal(t, "go.uber.org/zap", zap["name"])
+	require.Equal(t, "v1.27.0", zap["version"])
+	require.Nil(t, zap["hashes"])
+	require.Equal(t, []any{map[string]any{"name": "go.sum:h1", "value": "h1:aJMhYGrd5QSmlpLMr2MftRKl7t8J8PTZPA732ud/XR8="}}, zap["properties"])
+	require.Nil(t, components[1].(map[string]any)["properties"])
+
+	deps := out["dependencies"].([]any)[0].(map[string]any)
+	require.Equal(t, []any{"pkg:golang/go.uber.org/zap@v1.27.0", "pkg:golang/example.com/local"}, deps["dependsOn"])
+}
+
+func TestSPDX(t *testing.T) {
+	data, err := sbom.Encode(doc, sbom.FormatSPDX)
+	require.NoError(t, err)
+
+	out := map[string]any{}
+	require.NoError(t, json.Unmarshal(data, &out))
+	require.Equal(t, "SPDX-2.3", out["spdxVersion"])
+	require.Equal(t, "2025-09-03T18:37:58Z", out["creationInfo"].(map[string]any)["created"])
+
+	packages := out["packages"].([]any)
+	require.Len(t, packages, 3)
+	zap := packages[1].(map[string]any)
+	require.Equal(t, "go.uber.org/zap", zap["name"])
+	require.Equal(t, "SPDXRef-Package-go.uber.org-zap-v1.27.0", zap["SPDXID"])
+	require.Nil(t, zap["checksums"])
+	require.Equal(t, "go.sum:h1 h1:aJMhYGrd5QSmlpLMr2MftRKl7t8J8PTZPA732ud/XR8=", zap["comment"])
+
+	relationships := out["relationships"].([]any)
+	require.Len(t, relationships, 3)
+	require.Equal(t, "DESCRIBES", relationships[0].(map[string]any)["relationshipType"])
+	require.Equal(t, "DEPENDS_ON", relationships[1].(map[string]any)["relationshipType"])
+}
+
+func TestEncode_UnknownFormat(t *testing.T) {
+	_, err := sbom.Encode(doc, sbom.Format("xml"))
+	require.Error(t, err)
+}
@@ -0,0 +1,110 @@
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	Name             string            `json:"name"`
+	SPDXID           string            `json:"SPDXID"`
+	VersionInfo      string            `json:"versionInfo,omitempty"`
+	DownloadLocation string            `json:"downloadLocation"`
+	FilesAnalyzed    bool              `json:"filesAnalyzed"`
+	Comment          string            `json:"comment,omitempty"`
+	ExternalRefs     []spdxExternalRef `json:"externalRefs,omitempty"`
+}
+
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+var spdxIDInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9.-]+`)
+
+func spdxID(name string) string {
+	return "SPDXRef-Package-" + spdxIDInvalidChars.ReplaceAllString(name, "-")
+}
+
+// SPDX encodes the document as SPDX 2.3 JSON
+func SPDX(d Document) ([]byte, error) {
+	mainID := spdxID(d.Name)
+	doc := spdxDocument{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              d.Name,
+		DocumentNamespace: fmt.Sprintf("https://github.com/brunoluiz/monogo/spdx/%s-%s", d.Name, d.Revision),
+		CreationInfo: spdxCreationInfo{
+			Created:  d.Created.UTC().Format(time.RFC3339),
+			Creators: []string{"Tool: " + toolName},
+		},
+		Packages: []spdxPackage{{
+			Name:             d.Name,
+			SPDXID:           mainID,
+			VersionInfo:      d.Revision,
+			DownloadLocation: "NOASSERTION",
+			ExternalRefs:     []spdxExternalRef{purlRef(purl(d.Name, ""))},
+		}},
+		Relationships: []spdxRelationship{{
+			SPDXElementID:      "SPDXRef-DOCUMENT",
+			RelationshipType:   "DESCRIBES",
+			RelatedSPDXElement: mainID,
+		}},
+	}
+
+	for _, c := range d.Components {
+		pkg := spdxPackage{
+			Name:             c.Path,
+			SPDXID:           spdxID(c.Path + "@" + c.Version),
+			VersionInfo:      c.Version,
+			DownloadLocation: "NOASSERTION",
+			ExternalRefs:     []spdxExternalRef{purlRef(c.PURL())},
+		}
+		if c.Hash != "" {
+			pkg.Comment = HashProperty + " " + c.Hash
+		}
+
+		doc.Packages = append(doc.Packages, pkg)
+		doc.Relationships = append(doc.Relationships, spdxRelationship{
+			SPDXElementID:      mainID,
+			RelationshipType:   "DEPENDS_ON",
+			RelatedSPDXElement: pkg.SPDXID,
+		})
+	}
+
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+func purlRef(locator string) spdxExternalRef {
+	return spdxExternalRef{
+		ReferenceCategory: "PACKAGE-MANAGER",
+		ReferenceType:     "purl",
+		ReferenceLocator:  locator,
+	}
+}
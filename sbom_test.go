@@ -0,0 +1,51 @@
+package monogo_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/brunoluiz/monogo"
+	xgit "github.com/brunoluiz/monogo/git"
+	"github.com/brunoluiz/monogo/sbom"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetector_SBOM(t *testing.T) {
+	tmpDir, _, b := setupTestRepo(t)
+
+	g, err := xgit.New(xgit.WithPath(tmpDir))
+	require.NoError(t, err)
+	d := monogo.NewDetector([]string{"cmd/app1", "./cmd/app2"}, slog.Default(), g,
+		monogo.WithPath(tmpDir),
+	)
+
+	docs, err := d.SBOM(context.Background(), string(b))
+	require.NoError(t, err)
+	require.Len(t, docs, 2)
+
+	hash, _, err := g.Ref(string(b))
+	require.NoError(t, err)
+
+	require.Equal(t, "test-project/cmd/app1", docs[0].Name)
+	require.Equal(t, "test-project/cmd/app2", docs[1].Name)
+	require.Equal(t, hash, docs[1].Revision)
+
+	paths := lo.Map(docs[1].Components, func(c sbom.Component, _ int) string { return c.Path + "@" + c.Version })
+	require.Equal(t, []string{"go.uber.org/multierr@v1.10.0", "go.uber.org/zap@v1.27.0", "stdlib@v1.22.0"}, paths)
+	require.NotEmpty(t, docs[1].Components[1].Hash)
+
+	t.Run("discover", func(t *testing.T) {
+		d := monogo.NewDetector(nil, slog.Default(), g,
+			monogo.WithPath(tmpDir),
+			monogo.WithDiscover([]string{"cmd/*", "!cmd/app3"}),
+		)
+
+		docs, err := d.SBOM(context.Background(), string(b))
+		require.NoError(t, err)
+		require.Equal(t, []string{"cmd/app1", "cmd/app2"}, lo.Map(docs, func(d sbom.Document, _ int) string { return d.Entrypoint }))
+		require.Equal(t, "test-project/cmd/app2", docs[1].Name)
+		require.NotEmpty(t, docs[1].Components)
+	})
+}
@@ -0,0 +1,173 @@
+package monogo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/brunoluiz/monogo/git"
+	"github.com/brunoluiz/monogo/graph"
+	"golang.org/x/mod/modfile"
+)
+
+// SnapshotVersion is the only supported snapshot format version
+const SnapshotVersion = 1
+
+// Snapshot contains the base ref analysis done by Run. It can be exported by a main branch job and consumed
+// by PR jobs with WithBaseSnapshot, so these only load the compare ref.
+type Snapshot struct {
+	Version  int              `json:"version"`
+	Ref      string           `json:"ref"`
+	Hash     string           `json:"hash"`
+	Settings SnapshotSettings `json:"settings"`
+	// Files contains the content hash of every file in the tree (eg: git blob hashes), by path
+	Files map[string]string `json:"files"`
+	// Entrypoints contains the entrypoints and libraries found in the tree
+	Entrypoints        []string            `json:"entrypoints"`
+	FilesByEntrypoint  map[string][]string `json:"files_by_entrypoint"`
+	InputsByEntrypoint map[string][]string `json:"inputs_by_entrypoint"`
+	GoMod              string              `json:"go_mod"`
+	// Graph contains the import graph of all module packages, if requested
+	Graph *graph.Graph `json:"graph,omitempty"`
+}
+
+// SnapshotSettings are the detector settings changing the snapshot content, which must match when consuming it
+type SnapshotSettings struct {
+	Entrypoints      []string `json:"entrypoints"`
+	Libraries        []string `json:"libraries"`
+	Discover         bool     `json:"discover"`
+	DiscoverPatterns []string `json:"discover_patterns"`
+}
+
+// Snapshot analyses the ref like Run does for the base ref. If withGraph is set, the import graph of all module
+// packages is included, although it is not used by Run.
+func (r *Detector) Snapshot(ctx context.Context, ref string, withGraph bool) (Snapshot, error) {
+	lister, ok := r.Source.(treeLister)
+	if !ok {
+		return Snapshot{}, fmt.Errorf("snapshots are not supported by the source")
+	}
+
+	hash, refName, err := r.Source.Ref(ref)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("failed to get ref: %w", err)
+	}
+
+	files, err := lister.TreeFiles(ref)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("failed to list files: %w", err)
+	}
+
+	info, err := r.getRefInfo(ctx, ref)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("failure while getting tree info: %w", err)
+	}
+
+	goMod, err := info.modfile.Format()
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("failed to format go.mod: %w", err)
+	}
+
+	s := Snapshot{
+		Version:            SnapshotVersion,
+		Ref:                refName,
+		Hash:               hash,
+		Settings:           r.snapshotSettings(),
+		Files:              files,
+		Entrypoints:        info.entrypoints,
+		FilesByEntrypoint:  info.filesByEntrypoint,
+		InputsByEntrypoint: info.inputsByEntrypoint,
+		GoMod:              string(goMod),
+	}
+	if !withGraph {
+		return s, nil
+	}
+
+	err = r.Source.RunOnRef(ref, func(dir string) error {
+		w, err := r.newWalker(dir, ref, "walker:"+ref)
+		if err != nil {
+			return err
+		}
+		g, err := graph.Load(ctx, w, false)
+		s.Graph = &g
+		return err
+	})
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("failed to load graph: %w", err)
+	}
+	return s, nil
+}
+
+// ReadSnapshot decodes a snapshot, checking its version
+func ReadSnapshot(in io.Reader) (Snapshot, error) {
+	var s Snapshot
+	if err := json.NewDecoder(in).Decode(&s); err != nil {
+		return Snapshot{}, fmt.Errorf("failed to decode snapshot: %w", err)
+	}
+	if s.Version != SnapshotVersion {
+		return Snapshot{}, fmt.Errorf("unsupported snapshot version %d, expected %d", s.Version, SnapshotVersion)
+	}
+	return s, nil
+}
+
+func (r *Detector) snapshotSettings() SnapshotSettings {
+	return SnapshotSettings{
+		Entrypoints:      slices.Clone(r.Entrypoints),
+		Libraries:        slices.Clone(r.Libraries),
+		Discover:         r.Discover,
+		DiscoverPatterns: slices.Clone(r.DiscoverPatterns),
+	}
+}
+
+// mainBranchInfo returns the base ref analysis, if the snapshot was created with the same settings
+func (s *Snapshot) mainBranchInfo(settings SnapshotSettings) (mainBranchInfo, error) {
+	if !s.Settings.equal(settings) {
+		return mainBranchInfo{}, fmt.Errorf("base snapshot was created with other entrypoints or libraries")
+	}
+
+	modFile, err := modfile.Parse("go.mod", []byte(s.GoMod), nil)
+	if err != nil {
+		return mainBranchInfo{}, fmt.Errorf("failed to parse snapshot go.mod: %w", err)
+	}
+
+	return mainBranchInfo{
+		entrypoints:        s.Entrypoints,
+		filesByEntrypoint:  s.FilesByEntrypoint,
+		inputsByEntrypoint: s.InputsByEntrypoint,
+		modfile:            modFile,
+	}, nil
+}
+
+func (s SnapshotSettings) equal(other SnapshotSettings) bool {
+	return slices.Equal(s.Entrypoints, other.Entrypoints) &&
+		slices.Equal(s.Libraries, other.Libraries) &&
+		s.Discover == other.Discover &&
+		slices.Equal(s.DiscoverPatterns, other.DiscoverPatterns)
+}
+
+// snapshotSource diffs the compare ref against the files of the base snapshot, instead of the base ref
+type snapshotSource struct {
+	Source
+	snapshot *Snapshot
+}
+
+func (s snapshotSource) Diff(fromRef, _ string) (git.DiffResult, error) {
+	lister, ok := s.Source.(treeLister)
+	if !ok {
+		return git.DiffResult{}, fmt.Errorf("base snapshots are not supported by the source")
+	}
+
+	files, err := lister.TreeFiles(fromRef)
+	if err != nil {
+		return git.DiffResult{}, err
+	}
+	return git.DiffFiles(s.snapshot.Files, files), nil
+}
+
+func (s snapshotSource) TreeHash(ref string) (string, error) {
+	if hasher, ok := s.Source.(treeHasher); ok {
+		return hasher.TreeHash(ref)
+	}
+	return "", nil
+}
@@ -0,0 +1,75 @@
+package monogo_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/brunoluiz/monogo"
+	xgit "github.com/brunoluiz/monogo/git"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetector_Snapshot(t *testing.T) {
+	tmpDir, w, b := setupTestRepo(t)
+	commitFile(t, w, "pkg/pkgB/b.go", "package pkgB\n\nfunc B() string { return \"changed\" }\n")
+	commitFile(t, w, "pkg/pkgA/new.go", "package pkgA\n")
+
+	g, err := xgit.New(xgit.WithPath(tmpDir))
+	require.NoError(t, err)
+	entrypoints := []string{"cmd/app1", "cmd/app2", "cmd/app3"}
+
+	snapshot, err := monogo.NewDetector(entrypoints, slog.Default(), g, monogo.WithPath(tmpDir)).
+		Snapshot(context.Background(), "refs/heads/main", true)
+	require.NoError(t, err)
+	require.Equal(t, entrypoints, snapshot.Entrypoints)
+	require.Contains(t, snapshot.Files, "pkg/pkgA/a.go")
+	require.Contains(t, snapshot.FilesByEntrypoint["cmd/app1"], "pkg/pkgA/a.go")
+	require.Contains(t, snapshot.GoMod, "module test-project")
+	require.NotNil(t, snapshot.Graph)
+	require.Len(t, snapshot.Graph.Nodes, 6)
+
+	var buf bytes.Buffer
+	require.NoError(t, json.NewEncoder(&buf).Encode(snapshot))
+	snapshot, err = monogo.ReadSnapshot(&buf)
+	require.NoError(t, err)
+
+	t.Run("same results as the base ref", func(t *testing.T) {
+		expected, err := monogo.NewDetector(entrypoints, slog.Default(), g,
+			monogo.WithPath(tmpDir),
+			monogo.WithCompareRef(string(b)),
+		).Run(context.Background())
+		require.NoError(t, err)
+
+		res, err := monogo.NewDetector(entrypoints, slog.Default(), g,
+			monogo.WithPath(tmpDir),
+			monogo.WithBaseRef("refs/heads/missing"),
+			monogo.WithCompareRef(string(b)),
+			monogo.WithBaseSnapshot(&snapshot),
+		).Run(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, expected.Git.Files, res.Git.Files)
+		require.ElementsMatch(t, expected.Entrypoints, res.Entrypoints)
+		require.Len(t, res.Entrypoints, 3)
+	})
+
+	t.Run("other settings", func(t *testing.T) {
+		_, err := monogo.NewDetector([]string{"cmd/app1"}, slog.Default(), g,
+			monogo.WithPath(tmpDir),
+			monogo.WithCompareRef(string(b)),
+			monogo.WithBaseSnapshot(&snapshot),
+		).Run(context.Background())
+		require.ErrorContains(t, err, "base snapshot was created with other entrypoints")
+	})
+}
+
+func TestReadSnapshot(t *testing.T) {
+	_, err := monogo.ReadSnapshot(strings.NewReader(`{"version": 2}`))
+	require.ErrorContains(t, err, "unsupported snapshot version")
+
+	_, err = monogo.ReadSnapshot(strings.NewReader(`{`))
+	require.Error(t, err)
+}
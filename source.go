@@ -0,0 +1,53 @@
+package monogo
+
+import (
+	"github.com/brunoluiz/monogo/git"
+)
+
+// Source provides the trees compared by Detector.Run. By default, refs are checked out from the git repository.
+type Source interface {
+	// Ref returns the hash and name of the ref
+	Ref(ref string) (string, string, error)
+	// Diff returns the repository relative files changed in fromRef, compared to compareRef
+	Diff(fromRef, compareRef string) (git.DiffResult, error)
+	// RunOnRef makes the ref tree available in a directory while cb runs
+	RunOnRef(ref string, cb func(dir string) error) error
+}
+
+// treeHasher is implemented by sources identifying the tree of each ref by its content, which allows caching
+// the analysis of the tree
+type treeHasher interface {
+	TreeHash(ref string) (string, error)
+}
+
+// treeLister is implemented by sources listing the content hash of each file in the tree of a ref, which
+// allows diffing against base snapshots
+type treeLister interface {
+	TreeFiles(ref string) (map[string]string, error)
+}
+
+// gitSource checks out the refs within the repository path
+type gitSource struct {
+	git  *git.Git
+	path string
+}
+
+func (s gitSource) Ref(ref string) (string, string, error) {
+	return s.git.Ref(ref)
+}
+
+func (s gitSource) Diff(fromRef, compareRef string) (git.DiffResult, error) {
+	return s.git.Diff(fromRef, compareRef)
+}
+
+func (s gitSource) RunOnRef(ref string, cb func(dir string) error) error {
+	return s.git.RunOnRef(ref, func() error { return cb(s.path) })
+}
+
+func (s gitSource) TreeHash(ref string) (string, error) {
+	return s.git.TreeHash(ref)
+}
+
+func (s gitSource) TreeFiles(ref string) (map[string]string, error) {
+	return s.git.TreeFiles(ref)
+}
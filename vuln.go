@@ -0,0 +1,141 @@
+package monogo
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/brunoluiz/monogo/mod"
+	"github.com/brunoluiz/monogo/vuln"
+	"github.com/brunoluiz/monogo/walker"
+	"github.com/brunoluiz/monogo/walker/hook"
+	"github.com/samber/lo"
+)
+
+type VulnRes struct {
+	// Vulnerable is true if any entrypoint is affected by vulnerabilities on the compare ref
+	Vulnerable  bool                `json:"vulnerable"`
+	Base        VulnRefRes          `json:"base"`
+	Compare     VulnRefRes          `json:"compare"`
+	Entrypoints []VulnEntrypointRes `json:"entrypoints"`
+}
+
+type VulnRefRes struct {
+	Hash string `json:"hash"`
+	Ref  string `json:"ref"`
+}
+
+type VulnEntrypointRes struct {
+	Path       string         `json:"path"`
+	Vulnerable bool           `json:"vulnerable"`
+	Base       []vuln.Finding `json:"base"`
+	Compare    []vuln.Finding `json:"compare"`
+	// Introduced contains vulnerability IDs only found on the compare ref
+	Introduced []string `json:"introduced"`
+	// Fixed contains vulnerability IDs only found on the base ref
+	Fixed []string `json:"fixed"`
+}
+
+// Vuln matches the modules reached by each entrypoint, on both base and compare refs,
+// against the given vulnerability database. No network access is required.
+func (r *Detector) Vuln(ctx context.Context, db *vuln.DB) (VulnRes, error) {
+	res := VulnRes{Entrypoints: []VulnEntrypointRes{}}
+
+	var err error
+	if res.Base.Hash, res.Base.Ref, err = r.Git.Ref(r.BaseRef); err != nil {
+		return VulnRes{}, fmt.Errorf("failed to get base ref: %w", err)
+	}
+	if res.Compare.Hash, res.Compare.Ref, err = r.Git.Ref(r.CompareRef); err != nil {
+		return VulnRes{}, fmt.Errorf("failed to get compare ref: %w", err)
+	}
+
+	// The entrypoint might not exist in the base ref, which is treated as having no vulnerabilities
+	base, baseEntrypoints, err := r.vulnFindings(ctx, r.BaseRef, db, true)
+	if err != nil {
+		return VulnRes{}, fmt.Errorf("failure while matching vulnerabilities on base: %w", err)
+	}
+
+	compare, compareEntrypoints, err := r.vulnFindings(ctx, r.CompareRef, db, false)
+	if err != nil {
+		return VulnRes{}, fmt.Errorf("failure while matching vulnerabilities on compare: %w", err)
+	}
+
+	findingID := func(f vuln.Finding, _ int) string { return f.ID }
+	// Discovered entrypoints might only exist in one of the refs
+	for _, entry := range lo.Union(compareEntrypoints, baseEntrypoints) {
+		baseIDs := lo.Uniq(lo.Map(base[entry], findingID))
+		compareIDs := lo.Uniq(lo.Map(compare[entry], findingID))
+		introduced, fixed := lo.Difference(compareIDs, baseIDs)
+
+		res.Entrypoints = append(res.Entrypoints, VulnEntrypointRes{
+			Path:       entry,
+			Vulnerable: len(compare[entry]) > 0,
+			Base:       base[entry],
+			Compare:    compare[entry],
+			Introduced: introduced,
+			Fixed:      fixed,
+		})
+	}
+
+	res.Vulnerable = lo.SomeBy(res.Entrypoints, func(item VulnEntrypointRes) bool {
+		return item.Vulnerable
+	})
+	return res, nil
+}
+
+func (r *Detector) vulnFindings(ctx context.Context, ref string, db *vuln.DB, allowMissing bool) (map[string][]vuln.Finding, []string, error) {
+	findings := map[string][]vuln.Finding{}
+	stdVersion := ""
+
+	prepare := func(_ context.Context, _ *walker.Walker) error {
+		_, m, err := mod.Get(mod.WithModDir(r.Path))
+		if err != nil {
+			return err
+		}
+
+		// The standard library version is the one from the toolchain used to build
+		stdVersion = vuln.GoVersion(mod.ToolchainVersion(m))
+		return nil
+	}
+
+	rw := sync.RWMutex{}
+	entrypoints, err := r.walkEntrypoints(ctx, ref, nil, prepare, func(ctx context.Context, w *walker.Walker, entry string) error {
+		depsHook := hook.NewDeps()
+		if err := w.Walk(ctx, entry, depsHook); err != nil {
+			if !allowMissing {
+				return err
+			}
+			r.Logger.Debug("entrypoint could not be walked", "entry", entry, "ref", ref, "error", err)
+		}
+
+		// Group packages by module, as entries are indexed by module
+		pkgsByModule := map[string][]string{}
+		versions := map[string]string{vuln.ModuleStdlib: stdVersion}
+		for path, p := range depsHook.Packages() {
+			module := vuln.ModuleStdlib
+			if !hook.IsStd(p) {
+				module = p.Module.Path
+				versions[module] = hook.ModuleVersion(p.Module)
+			}
+			pkgsByModule[module] = append(pkgsByModule[module], path)
+		}
+
+		entryFindings := []vuln.Finding{}
+		for module, pkgs := range pkgsByModule {
+			entryFindings = append(entryFindings, db.Match(module, versions[module], pkgs)...)
+		}
+		slices.SortFunc(entryFindings, func(a, b vuln.Finding) int {
+			return strings.Compare(a.Module+a.ID, b.Module+b.ID)
+		})
+
+		// Write operations to shared memory below
+		rw.Lock()
+		defer rw.Unlock()
+		findings[entry] = entryFindings
+		return nil
+	})
+
+	return findings, entrypoints, err
+}
@@ -0,0 +1,288 @@
+package vuln
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/samber/lo"
+	"golang.org/x/mod/semver"
+)
+
+const (
+	// EcosystemGo is the OSV ecosystem used by Go modules
+	EcosystemGo = "Go"
+	// ModuleStdlib is the module name used for the standard library in the Go vulnerability database
+	ModuleStdlib = "stdlib"
+)
+
+// Entry is an OSV vulnerability entry (https://ossf.github.io/osv-schema)
+type Entry struct {
+	ID       string     `json:"id"`
+	Summary  string     `json:"summary"`
+	Details  string     `json:"details"`
+	Aliases  []string   `json:"aliases"`
+	Affected []Affected `json:"affected"`
+}
+
+type Affected struct {
+	Package           Package           `json:"package"`
+	Ranges            []Range           `json:"ranges"`
+	Versions          []string          `json:"versions"`
+	EcosystemSpecific EcosystemSpecific `json:"ecosystem_specific"`
+}
+
+type Package struct {
+	Ecosystem string `json:"ecosystem"`
+	Name      string `json:"name"`
+}
+
+type Range struct {
+	Type   string  `json:"type"`
+	Events []Event `json:"events"`
+}
+
+type Event struct {
+	Introduced   string `json:"introduced,omitempty"`
+	Fixed        string `json:"fixed,omitempty"`
+	LastAffected string `json:"last_affected,omitempty"`
+}
+
+type EcosystemSpecific struct {
+	Imports []Import `json:"imports"`
+}
+
+type Import struct {
+	Path    string   `json:"path"`
+	Symbols []string `json:"symbols"`
+}
+
+// Finding is a vulnerability affecting a module version
+type Finding struct {
+	ID      string   `json:"id"`
+	Aliases []string `json:"aliases"`
+	Summary string   `json:"summary"`
+	Module  string   `json:"module"`
+	Version string   `json:"version"`
+	// Fixed is the lowest version fixing the vulnerability, empty if there is no fix
+	Fixed string `json:"fixed"`
+	// Packages contains the vulnerable packages in use. It is empty if the
+	// entry does not specify packages, meaning the whole module is affected.
+	Packages []string `json:"packages"`
+}
+
+type DB struct {
+	entries map[string][]*Entry
+}
+
+// Load reads all OSV JSON files within the directory (recursively). Files which
+// are not OSV entries, such as database indexes, are ignored.
+func Load(dir string) (*DB, error) {
+	db := &DB{entries: map[string][]*Entry{}}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".json") {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+
+		entry := &Entry{}
+		if err := json.Unmarshal(data, entry); err != nil {
+			var typeErr *json.UnmarshalTypeError
+			if errors.As(err, &typeErr) {
+				return nil
+			}
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		db.Add(entry)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load vulnerability database: %w", err)
+	}
+
+	return db, nil
+}
+
+// Add indexes the entry by its affected Go modules
+func (db *DB) Add(entry *Entry) {
+	if entry.ID == "" {
+		return
+	}
+
+	modules := lo.FilterMap(entry.Affected, func(a Affected, _ int) (string, bool) {
+		return a.Package.Name, a.Package.Ecosystem == EcosystemGo
+	})
+	for _, module := range lo.Uniq(modules) {
+		db.entries[module] = append(db.entries[module], entry)
+	}
+}
+
+// Len returns the number of indexed entries
+func (db *DB) Len() int {
+	return len(lo.UniqBy(lo.Flatten(lo.Values(db.entries)), func(e *Entry) string { return e.ID }))
+}
+
+// Match returns the vulnerabilities affecting the module version. The packages in use
+// are used to discard entries which only affect other packages of the module.
+func (db *DB) Match(module, version string, pkgs []string) []Finding {
+	findings := []Finding{}
+	for _, entry := range db.entries[module] {
+		for _, affected := range entry.Affected {
+			if affected.Package.Ecosystem != EcosystemGo || affected.Package.Name != module {
+				continue
+			}
+			if !affected.Affects(version) {
+				continue
+			}
+
+			vulnerable := affected.Packages(pkgs)
+			if len(affected.EcosystemSpecific.Imports) > 0 && len(vulnerable) == 0 {
+				continue
+			}
+
+			findings = append(findings, Finding{
+				ID:       entry.ID,
+				Aliases:  lo.Ternary(entry.Aliases == nil, []string{}, entry.Aliases),
+				Summary:  entry.Summary,
+				Module:   module,
+				Version:  version,
+				Fixed:    affected.Fixed(version),
+				Packages: vulnerable,
+			})
+			break
+		}
+	}
+
+	slices.SortFunc(findings, func(a, b Finding) int { return strings.Compare(a.ID, b.ID) })
+	return findings
+}
+
+// Affects returns true if the version is within any of the affected ranges or versions
+func (a Affected) Affects(version string) bool {
+	v := canonical(version)
+	if v == "" {
+		return false
+	}
+
+	if lo.ContainsBy(a.Versions, func(item string) bool { return canonical(item) == v }) {
+		return true
+	}
+
+	return lo.SomeBy(a.Ranges, func(r Range) bool { return r.Affects(v) })
+}
+
+// Fixed returns the lowest fixed version above the given version
+func (a Affected) Fixed(version string) string {
+	v := canonical(version)
+	fixed := ""
+	for _, r := range a.Ranges {
+		for _, e := range r.Events {
+			f := canonical(e.Fixed)
+			if f == "" || semver.Compare(f, v) <= 0 {
+				continue
+			}
+			if fixed == "" || semver.Compare(f, canonical(fixed)) < 0 {
+				fixed = e.Fixed
+			}
+		}
+	}
+	return fixed
+}
+
+// Packages returns the vulnerable packages found within pkgs
+func (a Affected) Packages(pkgs []string) []string {
+	vulnerable := lo.FilterMap(a.EcosystemSpecific.Imports, func(i Import, _ int) (string, bool) {
+		return i.Path, lo.Contains(pkgs, i.Path)
+	})
+	slices.Sort(vulnerable)
+	return lo.Uniq(vulnerable)
+}
+
+// Affects evaluates the range events, as defined by the OSV schema, for a canonical semver version
+func (r Range) Affects(v string) bool {
+	if r.Type != "SEMVER" {
+		return false
+	}
+
+	events := slices.Clone(r.Events)
+	slices.SortStableFunc(events, func(a, b Event) int {
+		return semver.Compare(canonical(a.version()), canonical(b.version()))
+	})
+
+	affected := false
+	for _, e := range events {
+		switch {
+		case e.Introduced != "":
+			if e.Introduced == "0" || semver.Compare(v, canonical(e.Introduced)) >= 0 {
+				affected = true
+			}
+		case e.Fixed != "":
+			if semver.Compare(v, canonical(e.Fixed)) >= 0 {
+				affected = false
+			}
+		case e.LastAffected != "":
+			if semver.Compare(v, canonical(e.LastAffected)) > 0 {
+				affected = false
+			}
+		}
+	}
+	return affected
+}
+
+func (e Event) version() string {
+	switch {
+	case e.Introduced == "0":
+		return "0.0.0"
+	case e.Introduced != "":
+		return e.Introduced
+	case e.Fixed != "":
+		return e.Fixed
+	default:
+		return e.LastAffected
+	}
+}
+
+// canonical converts OSV versions (1.2.3) into semver (v1.2.3)
+func canonical(v string) string {
+	if v == "" {
+		return ""
+	}
+	if !strings.HasPrefix(v, "v") {
+		v = "v" + v
+	}
+	return semver.Canonical(v)
+}
+
+// GoVersion converts a Go toolchain version (go1.22.5, go1.24rc1) into
+// the semver format used by the stdlib entries in the Go vulnerability database
+func GoVersion(toolchain string) string {
+	v := strings.TrimPrefix(toolchain, "go")
+	if i := strings.IndexAny(v, "-+ "); i >= 0 {
+		v = v[:i]
+	}
+
+	pre := ""
+	for _, tag := range []string{"rc", "beta"} {
+		if i := strings.Index(v, tag); i >= 0 {
+			v, pre = v[:i], "-"+tag+"."+v[i+len(tag):]
+			break
+		}
+	}
+
+	if strings.Count(v, ".") == 1 {
+		v += ".0"
+	}
+	return semver.Canonical("v" + v + pre)
+}
@@ -0,0 +1,66 @@
+package vuln_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/brunoluiz/monogo/vuln"
+	"github.com/samber/lo"
+)
+
+func TestDB_Match(t *testing.T) {
+	db, err := vuln.Load("./testdata/db")
+	if err != nil {
+		t.Fatalf("failed to load database: %s", err)
+	}
+	if db.Len() != 3 {
+		t.Fatalf("expected 3 entries, got %d", db.Len())
+	}
+
+	testCases := []struct {
+		name          string
+		module        string
+		version       string
+		pkgs          []string
+		expectedIDs   []string
+		expectedFixed string
+	}{
+		{name: "vulnerable package in use", module: "example.com/a", version: "v1.1.0", pkgs: []string{"example.com/a/parser"}, expectedIDs: []string{"GO-2024-0001"}, expectedFixed: "1.2.0"},
+		{name: "vulnerable package not in use", module: "example.com/a", version: "v1.1.0", pkgs: []string{"example.com/a/other"}, expectedIDs: []string{}},
+		{name: "fixed version", module: "example.com/a", version: "v1.2.0", pkgs: []string{"example.com/a/parser"}, expectedIDs: []string{}},
+		{name: "re-introduced version", module: "example.com/a", version: "v1.3.1", pkgs: []string{"example.com/a/parser"}, expectedIDs: []string{"GO-2024-0001"}, expectedFixed: "1.3.2"},
+		{name: "module wide within last affected", module: "example.com/b", version: "v2.1.0", expectedIDs: []string{"GO-2024-0002"}},
+		{name: "module wide after last affected", module: "example.com/b", version: "v2.1.1", expectedIDs: []string{}},
+		{name: "stdlib", module: vuln.ModuleStdlib, version: vuln.GoVersion("go1.22.5"), pkgs: []string{"net/http"}, expectedIDs: []string{"GO-2024-0003"}, expectedFixed: "1.22.6"},
+		{name: "unknown module", module: "example.com/c", version: "v1.0.0", expectedIDs: []string{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			findings := db.Match(tc.module, tc.version, tc.pkgs)
+			ids := lo.Map(findings, func(f vuln.Finding, _ int) string { return f.ID })
+			if !reflect.DeepEqual(ids, tc.expectedIDs) {
+				t.Fatalf("expected %v, got %v", tc.expectedIDs, ids)
+			}
+			if len(findings) > 0 && findings[0].Fixed != tc.expectedFixed {
+				t.Errorf("expected fixed %q, got %q", tc.expectedFixed, findings[0].Fixed)
+			}
+		})
+	}
+}
+
+func TestGoVersion(t *testing.T) {
+	testCases := map[string]string{
+		"go1.22.5":   "v1.22.5",
+		"go1.22":     "v1.22.0",
+		"go1.24rc1":  "v1.24.0-rc.1",
+		"1.23.1":     "v1.23.1",
+		"go1.21.0+x": "v1.21.0",
+	}
+
+	for in, expected := range testCases {
+		if got := vuln.GoVersion(in); got != expected {
+			t.Errorf("GoVersion(%q): expected %s, got %s", in, expected, got)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package monogo_test
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/brunoluiz/monogo"
+	xgit "github.com/brunoluiz/monogo/git"
+	"github.com/brunoluiz/monogo/vuln"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetector_Vuln(t *testing.T) {
+	tmpDir, w, b := setupTestRepo(t)
+
+	// cmd/app2 stops using the shared package, which depends on the vulnerable module
+	commitFile(t, w, filepath.Join("cmd", "app2", "main.go"), `package main
+
+import (
+	"fmt"
+
+	"test-project/pkg/pkgB"
+)
+
+func main() {
+	fmt.Println("app2")
+	fmt.Println(pkgB.B())
+}
+`)
+
+	dbDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dbDir, "GO-2099-0001.json"), []byte(`{
+  "id": "GO-2099-0001",
+  "summary": "Made up vulnerability in multierr",
+  "affected": [{
+    "package": { "name": "go.uber.org/multierr", "ecosystem": "Go" },
+    "ranges": [{ "type": "SEMVER", "events": [{ "introduced": "0" }, { "fixed": "1.11.0" }] }]
+  }]
+}`), 0o600))
+	db, err := vuln.Load(dbDir)
+	require.NoError(t, err)
+
+	g, err := xgit.New(xgit.WithPath(tmpDir))
+	require.NoError(t, err)
+	d := monogo.NewDetector([]string{"cmd/app1", "cmd/app2"}, slog.Default(), g,
+		monogo.WithPath(tmpDir),
+		monogo.WithBaseRef(string(plumbing.NewBranchReferenceName("main"))),
+		monogo.WithCompareRef(string(b)),
+	)
+
+	res, err := d.Vuln(context.Background(), db)
+	require.NoError(t, err)
+	require.True(t, res.Vulnerable)
+
+	app1 := res.Entrypoints[0]
+	require.Equal(t, "cmd/app1", app1.Path)
+	require.True(t, app1.Vulnerable)
+	require.Len(t, app1.Compare, 1)
+	require.Equal(t, "go.uber.org/multierr", app1.Compare[0].Module)
+	require.Equal(t, "v1.10.0", app1.Compare[0].Version)
+	require.Equal(t, "1.11.0", app1.Compare[0].Fixed)
+	require.Empty(t, app1.Introduced)
+	require.Empty(t, app1.Fixed)
+
+	app2 := res.Entrypoints[1]
+	require.Equal(t, "cmd/app2", app2.Path)
+	require.False(t, app2.Vulnerable)
+	require.Len(t, app2.Base, 1)
+	require.Empty(t, app2.Compare)
+	require.Equal(t, []string{"GO-2099-0001"}, app2.Fixed)
+
+	t.Run("discover", func(t *testing.T) {
+		d := monogo.NewDetector(nil, slog.Default(), g,
+			monogo.WithPath(tmpDir),
+			monogo.WithBaseRef(string(plumbing.NewBranchReferenceName("main"))),
+			monogo.WithCompareRef(string(b)),
+			monogo.WithDiscover([]string{"cmd/*"}),
+		)
+
+		res, err := d.Vuln(context.Background(), db)
+		require.NoError(t, err)
+		require.Equal(t, []string{"cmd/app1", "cmd/app2", "cmd/app3"}, lo.Map(res.Entrypoints, func(e monogo.VulnEntrypointRes, _ int) string { return e.Path }))
+		require.False(t, res.Entrypoints[1].Vulnerable)
+		require.Equal(t, []string{"GO-2099-0001"}, res.Entrypoints[1].Fixed)
+	})
+}
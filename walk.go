@@ -0,0 +1,77 @@
+package monogo
+
+import (
+	"context"
+	"slices"
+
+	"github.com/brunoluiz/monogo/glob"
+	"github.com/brunoluiz/monogo/walker"
+	"golang.org/x/sync/errgroup"
+)
+
+// walkEntrypoints checks out the ref and runs fn for each entrypoint, plus the extra ones, concurrently
+// and sharing the same walker. fn is responsible for synchronising writes to shared memory. If set,
+// prepare runs once after the checkout and before any fn call. The walked entrypoints are returned,
+// without the extra ones, as they might have been discovered from the ref.
+func (r *Detector) walkEntrypoints(
+	ctx context.Context,
+	ref string,
+	extra []string,
+	prepare func(ctx context.Context, w *walker.Walker) error,
+	fn func(ctx context.Context, w *walker.Walker, entry string) error,
+	opts ...walker.WithOpt,
+) ([]string, error) {
+	var entrypoints []string
+	err := r.Git.RunOnRef(ref, func() error {
+		w, err := r.newWalker(r.Path, ref, "walker:"+ref, opts...)
+		if err != nil {
+			return err
+		}
+
+		entrypoints, err = r.entrypoints(ctx, w)
+		if err != nil {
+			return err
+		}
+
+		if prepare != nil {
+			if err := prepare(ctx, w); err != nil {
+				return err
+			}
+		}
+
+		// Runs each entrypoint walker with go routines: you must test it with `-race` enabled
+		eg, ctx := errgroup.WithContext(ctx)
+		for _, entry := range slices.Concat(entrypoints, extra) {
+			entry := entry
+			eg.Go(func() error {
+				return fn(ctx, w, entry)
+			})
+		}
+
+		return eg.Wait()
+	})
+	return entrypoints, err
+}
+
+// entrypoints returns the configured entrypoints or, if discovery is enabled, the main packages
+// from the checked out ref matching the discovery patterns
+func (r *Detector) entrypoints(ctx context.Context, w *walker.Walker) ([]string, error) {
+	if !r.Discover {
+		return r.Entrypoints, nil
+	}
+
+	mains, err := w.Discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return glob.Select(r.DiscoverPatterns, mains), nil
+}
+
+// targets returns the entrypoints plus the libraries, which are detected the same way
+func (r *Detector) targets(ctx context.Context, w *walker.Walker) ([]string, error) {
+	entrypoints, err := r.entrypoints(ctx, w)
+	if err != nil {
+		return nil, err
+	}
+	return slices.Concat(entrypoints, r.Libraries), nil
+}
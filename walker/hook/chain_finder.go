@@ -0,0 +1,55 @@
+package hook
+
+import (
+	"golang.org/x/tools/go/packages"
+)
+
+type ChainFinder struct {
+	parents map[string]*packages.Package
+	visited map[string]struct{}
+}
+
+// NewChainFinder records the import graph reachable from packages checked during Do,
+// so the shortest import chain to any of them can be retrieved afterwards.
+func NewChainFinder() *ChainFinder {
+	return &ChainFinder{
+		parents: map[string]*packages.Package{},
+		visited: map[string]struct{}{},
+	}
+}
+
+// Chain returns the shortest import chain from the first package checked to the given package path,
+// or nil if it was not reached
+func (h *ChainFinder) Chain(pkgPath string) []string {
+	if _, ok := h.visited[pkgPath]; !ok {
+		return nil
+	}
+	return chain(h.parents, &packages.Package{PkgPath: pkgPath})
+}
+
+func (h *ChainFinder) Do(p *packages.Package) error {
+	// Packages visited by a previous search were already explored from an importer,
+	// which always results in shorter or equal chains
+	if _, ok := h.visited[p.PkgPath]; ok {
+		return nil
+	}
+
+	// Breadth first search, so the first parent found for each package is in its shortest chain
+	queue := []*packages.Package{p}
+	h.visited[p.PkgPath] = struct{}{}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, imported := range sortedImports(current) {
+			if _, ok := h.visited[imported.PkgPath]; ok {
+				continue
+			}
+			h.visited[imported.PkgPath] = struct{}{}
+			h.parents[imported.PkgPath] = current
+			queue = append(queue, imported)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package hook_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/brunoluiz/monogo/walker/hook"
+	"golang.org/x/tools/go/packages"
+)
+
+func TestChainFinder(t *testing.T) {
+	shared := &packages.Package{PkgPath: "example.com/project/shared"}
+	pkgA := &packages.Package{PkgPath: "example.com/project/pkgA", Imports: map[string]*packages.Package{
+		"example.com/project/shared": shared,
+	}}
+	pkgB := &packages.Package{PkgPath: "example.com/project/pkgB", Imports: map[string]*packages.Package{
+		"example.com/project/pkgA": pkgA,
+	}}
+	entry := &packages.Package{PkgPath: "example.com/project/cmd/app", Imports: map[string]*packages.Package{
+		"example.com/project/pkgA": pkgA,
+		"example.com/project/pkgB": pkgB,
+	}}
+
+	testCases := []struct {
+		name          string
+		pkgPath       string
+		expectedChain []string
+	}{
+		{
+			name:          "entrypoint",
+			pkgPath:       "example.com/project/cmd/app",
+			expectedChain: []string{"example.com/project/cmd/app"},
+		},
+		{
+			name:          "direct import",
+			pkgPath:       "example.com/project/pkgB",
+			expectedChain: []string{"example.com/project/cmd/app", "example.com/project/pkgB"},
+		},
+		{
+			name:          "shortest chain",
+			pkgPath:       "example.com/project/shared",
+			expectedChain: []string{"example.com/project/cmd/app", "example.com/project/pkgA", "example.com/project/shared"},
+		},
+		{
+			name:          "not reached",
+			pkgPath:       "example.com/project/pkgC",
+			expectedChain: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := hook.NewChainFinder()
+			for _, p := range []*packages.Package{entry, pkgB, pkgA, shared} {
+				if err := h.Do(p); err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+			}
+
+			if !reflect.DeepEqual(h.Chain(tc.pkgPath), tc.expectedChain) {
+				t.Errorf("expected chain %v, got %v", tc.expectedChain, h.Chain(tc.pkgPath))
+			}
+		})
+	}
+}
@@ -6,31 +6,39 @@ import (
 )
 
 type ChangeDetector struct {
-	files []string
-	found bool
+	files   []string
+	found   bool
+	matches map[string]string
 }
 
 // NewChangeDetector detects if packages checked during Do have any
 // matching file passed as an initial argument.
 // `files` must be absolute paths.
 func NewChangeDetector(files []string) *ChangeDetector {
-	return &ChangeDetector{files: files}
+	return &ChangeDetector{files: files, matches: map[string]string{}}
 }
 
 func (h *ChangeDetector) Found() bool {
 	return h.found
 }
 
+// Matches returns the package path of each matched file, by file
+func (h *ChangeDetector) Matches() map[string]string {
+	return h.matches
+}
+
 func (h *ChangeDetector) Do(p *packages.Package) error {
-	_, h.found = lo.Find(h.files, func(changedFile string) bool {
-		if _, ok := lo.Find(p.CompiledGoFiles, match(changedFile)); ok {
-			return true
+	for _, changedFile := range h.files {
+		if !lo.ContainsBy(p.CompiledGoFiles, match(changedFile)) && !lo.ContainsBy(p.EmbedFiles, match(changedFile)) {
+			continue
 		}
-		if _, ok := lo.Find(p.EmbedFiles, match(changedFile)); ok {
-			return true
+
+		h.found = true
+		// Test variants share the files of the package they test, so the first package found is kept
+		if _, ok := h.matches[changedFile]; !ok {
+			h.matches[changedFile] = p.PkgPath
 		}
-		return h.found
-	})
+	}
 
 	return nil
 }
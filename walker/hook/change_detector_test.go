@@ -1,6 +1,7 @@
 package hook_test
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/brunoluiz/monogo/walker/hook"
@@ -14,21 +15,25 @@ func TestChangeDetector(t *testing.T) {
 		name          string
 		pkg           *packages.Package
 		expectedFound bool
+		expectedMatch map[string]string
 	}{
 		{
 			name:          "no match",
 			pkg:           &packages.Package{ID: "pkg1", CompiledGoFiles: []string{"/path/to/file3.go"}},
 			expectedFound: false,
+			expectedMatch: map[string]string{},
 		},
 		{
 			name:          "match on go file",
-			pkg:           &packages.Package{ID: "pkg2", CompiledGoFiles: []string{"/path/to/file1.go"}},
+			pkg:           &packages.Package{ID: "pkg2", PkgPath: "example.com/pkg2", CompiledGoFiles: []string{"/path/to/file1.go"}},
 			expectedFound: true,
+			expectedMatch: map[string]string{"/path/to/file1.go": "example.com/pkg2"},
 		},
 		{
 			name:          "match on embedded file",
-			pkg:           &packages.Package{ID: "pkg4", EmbedFiles: []string{"/path/to/asset.txt"}},
+			pkg:           &packages.Package{ID: "pkg4", PkgPath: "example.com/pkg4", EmbedFiles: []string{"/path/to/asset.txt"}},
 			expectedFound: true,
+			expectedMatch: map[string]string{"/path/to/asset.txt": "example.com/pkg4"},
 		},
 	}
 
@@ -43,6 +48,9 @@ func TestChangeDetector(t *testing.T) {
 			if cd.Found() != tc.expectedFound {
 				t.Errorf("expected found to be %v, but got %v", tc.expectedFound, cd.Found())
 			}
+			if !reflect.DeepEqual(cd.Matches(), tc.expectedMatch) {
+				t.Errorf("expected matches %v, got %v", tc.expectedMatch, cd.Matches())
+			}
 		})
 	}
 }
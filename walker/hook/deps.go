@@ -0,0 +1,74 @@
+package hook
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/samber/lo"
+	"golang.org/x/tools/go/packages"
+)
+
+type Deps struct {
+	packages map[string]*packages.Package
+}
+
+// NewDeps collects all packages outside the module, including the standard library,
+// transitively imported by packages checked during Do.
+func NewDeps() *Deps {
+	return &Deps{packages: map[string]*packages.Package{}}
+}
+
+// Packages returns the collected packages indexed by package path
+func (h *Deps) Packages() map[string]*packages.Package {
+	return h.packages
+}
+
+// Modules returns the collected modules (excluding the standard library), sorted by path
+func (h *Deps) Modules() []*packages.Module {
+	modules := map[string]*packages.Module{}
+	for _, p := range h.packages {
+		if p.Module != nil {
+			modules[p.Module.Path] = p.Module
+		}
+	}
+
+	out := lo.Values(modules)
+	slices.SortFunc(out, func(a, b *packages.Module) int { return strings.Compare(a.Path, b.Path) })
+	return out
+}
+
+func (h *Deps) Do(p *packages.Package) error {
+	for _, imported := range p.Imports {
+		h.Visit(imported)
+	}
+	return nil
+}
+
+// Visit collects the package, if outside the module, and its transitive imports
+func (h *Deps) Visit(p *packages.Package) {
+	// Packages from the main module are visited by the walker itself
+	if p.Module != nil && p.Module.Main {
+		return
+	}
+	if _, ok := h.packages[p.PkgPath]; ok {
+		return
+	}
+
+	h.packages[p.PkgPath] = p
+	for _, imported := range p.Imports {
+		h.Visit(imported)
+	}
+}
+
+// IsStd returns true if the package is part of the standard library
+func IsStd(p *packages.Package) bool {
+	return p.Module == nil
+}
+
+// ModuleVersion returns the module version in use, taking replacements into account
+func ModuleVersion(m *packages.Module) string {
+	if m.Replace != nil && m.Replace.Version != "" {
+		return m.Replace.Version
+	}
+	return m.Version
+}
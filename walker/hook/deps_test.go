@@ -0,0 +1,60 @@
+package hook_test
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/brunoluiz/monogo/walker/hook"
+	"github.com/samber/lo"
+	"golang.org/x/tools/go/packages"
+)
+
+func TestDeps(t *testing.T) {
+	mainModule := &packages.Module{Path: "example.com/project", Main: true}
+	zapModule := &packages.Module{Path: "go.uber.org/zap", Version: "v1.27.0"}
+	multierrModule := &packages.Module{Path: "go.uber.org/multierr", Version: "v1.10.0"}
+
+	fmtPkg := &packages.Package{PkgPath: "fmt"}
+	multierr := &packages.Package{PkgPath: "go.uber.org/multierr", Module: multierrModule, Imports: map[string]*packages.Package{"fmt": fmtPkg}}
+	zap := &packages.Package{PkgPath: "go.uber.org/zap", Module: zapModule, Imports: map[string]*packages.Package{"go.uber.org/multierr": multierr}}
+	shared := &packages.Package{PkgPath: "example.com/project/shared", Module: mainModule, Imports: map[string]*packages.Package{"go.uber.org/zap": zap}}
+	entry := &packages.Package{PkgPath: "example.com/project/cmd/app", Module: mainModule, Imports: map[string]*packages.Package{
+		"example.com/project/shared": shared,
+		"fmt":                        fmtPkg,
+	}}
+
+	h := hook.NewDeps()
+	for _, p := range []*packages.Package{entry, shared} {
+		if err := h.Do(p); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	pkgs := lo.Keys(h.Packages())
+	sort.Strings(pkgs)
+	if expected := []string{"fmt", "go.uber.org/multierr", "go.uber.org/zap"}; !reflect.DeepEqual(pkgs, expected) {
+		t.Errorf("expected packages %v, got %v", expected, pkgs)
+	}
+
+	modules := lo.Map(h.Modules(), func(m *packages.Module, _ int) string { return m.Path })
+	if expected := []string{"go.uber.org/multierr", "go.uber.org/zap"}; !reflect.DeepEqual(modules, expected) {
+		t.Errorf("expected modules %v, got %v", expected, modules)
+	}
+
+	if !hook.IsStd(fmtPkg) || hook.IsStd(zap) {
+		t.Error("unexpected standard library detection")
+	}
+}
+
+func TestModuleVersion(t *testing.T) {
+	m := &packages.Module{Path: "example.com/a", Version: "v1.0.0"}
+	if v := hook.ModuleVersion(m); v != "v1.0.0" {
+		t.Errorf("expected v1.0.0, got %s", v)
+	}
+
+	m.Replace = &packages.Module{Path: "example.com/fork", Version: "v1.0.1"}
+	if v := hook.ModuleVersion(m); v != "v1.0.1" {
+		t.Errorf("expected v1.0.1, got %s", v)
+	}
+}
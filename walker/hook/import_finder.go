@@ -0,0 +1,116 @@
+package hook
+
+import (
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+type ImportFinder struct {
+	target  string
+	visited map[string]struct{}
+	chains  map[string][]string
+}
+
+// NewImportFinder finds the import chains from packages checked during Do to
+// the target, which can be either a package or a module path. A chain is
+// returned for each matched package imported from outside the target, from
+// the first package checked.
+func NewImportFinder(target string) *ImportFinder {
+	return &ImportFinder{
+		target:  target,
+		visited: map[string]struct{}{},
+		chains:  map[string][]string{},
+	}
+}
+
+func (h *ImportFinder) Found() bool {
+	return len(h.chains) > 0
+}
+
+// Chains returns the shortest import chain for each matched package, sorted by the matched package path
+func (h *ImportFinder) Chains() [][]string {
+	keys := make([]string, 0, len(h.chains))
+	for k := range h.chains {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	chains := make([][]string, 0, len(keys))
+	for _, k := range keys {
+		chains = append(chains, h.chains[k])
+	}
+	return chains
+}
+
+// Matches returns true if the package is the target or part of the target module
+func (h *ImportFinder) Matches(p *packages.Package) bool {
+	if p.PkgPath == h.target {
+		return true
+	}
+	if p.Module != nil {
+		return p.Module.Path == h.target
+	}
+	return strings.HasPrefix(p.PkgPath, h.target+"/")
+}
+
+func (h *ImportFinder) Do(p *packages.Package) error {
+	// Packages visited by a previous search were already explored from an importer,
+	// which always results in shorter or equal chains
+	if _, ok := h.visited[p.PkgPath]; ok {
+		return nil
+	}
+
+	// Breadth first search, so the first chain found for each package is the shortest
+	parents := map[string]*packages.Package{}
+	queue := []*packages.Package{p}
+	h.visited[p.PkgPath] = struct{}{}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		// Packages only imported from within the target module are not entry points to it
+		parent, hasParent := parents[current.PkgPath]
+		if h.Matches(current) && (!hasParent || !h.Matches(parent)) {
+			if _, ok := h.chains[current.PkgPath]; !ok {
+				h.chains[current.PkgPath] = chain(parents, current)
+			}
+		}
+
+		for _, imported := range sortedImports(current) {
+			if _, ok := h.visited[imported.PkgPath]; ok {
+				continue
+			}
+			h.visited[imported.PkgPath] = struct{}{}
+			parents[imported.PkgPath] = current
+			queue = append(queue, imported)
+		}
+	}
+
+	return nil
+}
+
+func chain(parents map[string]*packages.Package, p *packages.Package) []string {
+	out := []string{p.PkgPath}
+	for parent, ok := parents[p.PkgPath]; ok; parent, ok = parents[parent.PkgPath] {
+		out = append(out, parent.PkgPath)
+	}
+	slices.Reverse(out)
+	return out
+}
+
+// sortedImports returns the imports in a stable order, so chains are deterministic
+func sortedImports(p *packages.Package) []*packages.Package {
+	keys := make([]string, 0, len(p.Imports))
+	for k := range p.Imports {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	imports := make([]*packages.Package, 0, len(keys))
+	for _, k := range keys {
+		imports = append(imports, p.Imports[k])
+	}
+	return imports
+}
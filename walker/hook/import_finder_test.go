@@ -0,0 +1,72 @@
+package hook_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/brunoluiz/monogo/walker/hook"
+	"golang.org/x/tools/go/packages"
+)
+
+func TestImportFinder(t *testing.T) {
+	s3Module := &packages.Module{Path: "github.com/aws/aws-sdk-go-v2/service/s3"}
+	s3Types := &packages.Package{PkgPath: "github.com/aws/aws-sdk-go-v2/service/s3/types", Module: s3Module}
+	s3 := &packages.Package{PkgPath: "github.com/aws/aws-sdk-go-v2/service/s3", Module: s3Module, Imports: map[string]*packages.Package{
+		"github.com/aws/aws-sdk-go-v2/service/s3/types": s3Types,
+	}}
+	storage := &packages.Package{PkgPath: "example.com/project/storage", Imports: map[string]*packages.Package{
+		"github.com/aws/aws-sdk-go-v2/service/s3": s3,
+	}}
+	entry := &packages.Package{PkgPath: "example.com/project/cmd/app", Imports: map[string]*packages.Package{
+		"example.com/project/storage":                   storage,
+		"github.com/aws/aws-sdk-go-v2/service/s3/types": s3Types,
+	}}
+
+	testCases := []struct {
+		name           string
+		target         string
+		expectedChains [][]string
+	}{
+		{
+			name:   "module",
+			target: "github.com/aws/aws-sdk-go-v2/service/s3",
+			expectedChains: [][]string{
+				{"example.com/project/cmd/app", "example.com/project/storage", "github.com/aws/aws-sdk-go-v2/service/s3"},
+				{"example.com/project/cmd/app", "github.com/aws/aws-sdk-go-v2/service/s3/types"},
+			},
+		},
+		{
+			name:           "package",
+			target:         "github.com/aws/aws-sdk-go-v2/service/s3/types",
+			expectedChains: [][]string{{"example.com/project/cmd/app", "github.com/aws/aws-sdk-go-v2/service/s3/types"}},
+		},
+		{
+			name:           "internal package",
+			target:         "example.com/project/storage",
+			expectedChains: [][]string{{"example.com/project/cmd/app", "example.com/project/storage"}},
+		},
+		{
+			name:           "not used",
+			target:         "github.com/aws/aws-sdk-go-v2/service/sqs",
+			expectedChains: [][]string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := hook.NewImportFinder(tc.target)
+			for _, p := range []*packages.Package{entry, storage} {
+				if err := h.Do(p); err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+			}
+
+			if h.Found() != (len(tc.expectedChains) > 0) {
+				t.Errorf("unexpected found: %v", h.Found())
+			}
+			if !reflect.DeepEqual(h.Chains(), tc.expectedChains) {
+				t.Errorf("expected chains %v, got %v", tc.expectedChains, h.Chains())
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package hook
+
+import (
+	"slices"
+
+	"github.com/samber/lo"
+	"golang.org/x/tools/go/packages"
+)
+
+type ImportGraph struct {
+	external bool
+	imports  map[string][]string
+}
+
+// NewImportGraph collects the imports between packages of the main module checked during Do. If external
+// is set, packages outside the module (including the standard library) and their transitive imports are
+// collected too, otherwise these are left out.
+func NewImportGraph(external bool) *ImportGraph {
+	return &ImportGraph{external: external, imports: map[string][]string{}}
+}
+
+// Imports returns the sorted imports of each package, by package path
+func (h *ImportGraph) Imports() map[string][]string {
+	return h.imports
+}
+
+func (h *ImportGraph) Do(p *packages.Package) error {
+	h.visit(p)
+	return nil
+}
+
+func (h *ImportGraph) visit(p *packages.Package) {
+	if _, ok := h.imports[p.PkgPath]; ok {
+		return
+	}
+
+	imported := lo.Filter(lo.Values(p.Imports), func(i *packages.Package, _ int) bool {
+		return h.external || isMain(i)
+	})
+	imports := lo.Map(imported, func(i *packages.Package, _ int) string { return i.PkgPath })
+	slices.Sort(imports)
+	h.imports[p.PkgPath] = imports
+
+	// Packages from the main module are visited by the walker itself
+	for _, i := range imported {
+		if !isMain(i) {
+			h.visit(i)
+		}
+	}
+}
+
+func isMain(p *packages.Package) bool {
+	return p.Module != nil && p.Module.Main
+}
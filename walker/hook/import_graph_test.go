@@ -0,0 +1,69 @@
+package hook_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/brunoluiz/monogo/walker/hook"
+	"golang.org/x/tools/go/packages"
+)
+
+func TestImportGraph(t *testing.T) {
+	main := &packages.Module{Path: "example.com/project", Main: true}
+	fmt := &packages.Package{PkgPath: "fmt"}
+	shared := &packages.Package{PkgPath: "example.com/project/shared", Module: main, Imports: map[string]*packages.Package{
+		"fmt": fmt,
+	}}
+	errors := &packages.Package{PkgPath: "github.com/pkg/errors", Module: &packages.Module{Path: "github.com/pkg/errors"}, Imports: map[string]*packages.Package{
+		"fmt": fmt,
+	}}
+	pkgA := &packages.Package{PkgPath: "example.com/project/pkgA", Module: main, Imports: map[string]*packages.Package{
+		"example.com/project/shared": shared,
+		"github.com/pkg/errors":      errors,
+	}}
+	entry := &packages.Package{PkgPath: "example.com/project/cmd/app", Module: main, Imports: map[string]*packages.Package{
+		"example.com/project/shared": shared,
+		"example.com/project/pkgA":   pkgA,
+	}}
+
+	testCases := []struct {
+		name     string
+		external bool
+		expected map[string][]string
+	}{
+		{
+			name: "module only",
+			expected: map[string][]string{
+				"example.com/project/cmd/app": {"example.com/project/pkgA", "example.com/project/shared"},
+				"example.com/project/pkgA":    {"example.com/project/shared"},
+				"example.com/project/shared":  {},
+			},
+		},
+		{
+			name:     "external",
+			external: true,
+			expected: map[string][]string{
+				"example.com/project/cmd/app": {"example.com/project/pkgA", "example.com/project/shared"},
+				"example.com/project/pkgA":    {"example.com/project/shared", "github.com/pkg/errors"},
+				"example.com/project/shared":  {"fmt"},
+				"github.com/pkg/errors":       {"fmt"},
+				"fmt":                         {},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := hook.NewImportGraph(tc.external)
+			for _, p := range []*packages.Package{entry, pkgA, shared, entry} {
+				if err := h.Do(p); err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+			}
+
+			if !reflect.DeepEqual(h.Imports(), tc.expected) {
+				t.Errorf("expected imports %v, got %v", tc.expected, h.Imports())
+			}
+		})
+	}
+}
@@ -1,30 +1,62 @@
 package hook
 
 import (
+	"slices"
+	"strings"
+
 	"github.com/samber/lo"
 	"golang.org/x/tools/go/packages"
 )
 
 type ModDetector struct {
 	packages []string
-	found    bool
+	modules  map[string]struct{}
 }
 
+// NewModDetector detects if packages checked during Do import any of the
+// modules passed as an initial argument.
 func NewModDetector(packages []string) *ModDetector {
-	return &ModDetector{packages: packages}
+	return &ModDetector{packages: packages, modules: map[string]struct{}{}}
 }
 
 func (h *ModDetector) Found() bool {
-	return h.found
+	return len(h.modules) > 0
+}
+
+// Modules returns the matched modules
+func (h *ModDetector) Modules() []string {
+	modules := lo.Keys(h.modules)
+	slices.Sort(modules)
+	return modules
 }
 
 func (h *ModDetector) Do(p *packages.Package) error {
-	_, h.found = lo.Find(h.packages, func(changedPackage string) bool {
-		if _, ok := lo.Find(lo.Keys(p.Imports), containsStr(changedPackage)); ok {
-			return true
+	for importPath, imported := range p.Imports {
+		if module, ok := h.match(importPath, imported); ok {
+			h.modules[module] = struct{}{}
 		}
-		return h.found
-	})
+	}
 
 	return nil
 }
+
+// match returns the module for the import if it is one of the monitored modules.
+// It relies on the module information when loaded, otherwise it uses the longest
+// module path which prefixes the import path.
+func (h *ModDetector) match(importPath string, imported *packages.Package) (string, bool) {
+	if imported != nil && imported.Module != nil {
+		return imported.Module.Path, lo.Contains(h.packages, imported.Module.Path)
+	}
+
+	module := ""
+	for _, changedPackage := range h.packages {
+		if isWithinModule(importPath, changedPackage) && len(changedPackage) > len(module) {
+			module = changedPackage
+		}
+	}
+	return module, module != ""
+}
+
+func isWithinModule(importPath, module string) bool {
+	return importPath == module || strings.HasPrefix(importPath, module+"/")
+}
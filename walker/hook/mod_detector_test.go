@@ -1,6 +1,7 @@
 package hook_test
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/brunoluiz/monogo/walker/hook"
@@ -11,10 +12,11 @@ func TestModDetector(t *testing.T) {
 	pkgs := []string{"example.com/a", "example.com/b"}
 
 	testCases := []struct {
-		name          string
-		pkg           *packages.Package
-		initialFound  bool
-		expectedFound bool
+		name            string
+		pkg             *packages.Package
+		initialFound    bool
+		expectedFound   bool
+		expectedModules []string
 	}{
 		{
 			name:          "no match",
@@ -22,9 +24,28 @@ func TestModDetector(t *testing.T) {
 			expectedFound: false,
 		},
 		{
-			name:          "match",
-			pkg:           &packages.Package{ID: "pkg2", Imports: map[string]*packages.Package{"example.com/a": {}}},
-			expectedFound: true,
+			name:            "match",
+			pkg:             &packages.Package{ID: "pkg2", Imports: map[string]*packages.Package{"example.com/a": {}}},
+			expectedFound:   true,
+			expectedModules: []string{"example.com/a"},
+		},
+		{
+			name:            "match on sub-package",
+			pkg:             &packages.Package{ID: "pkg3", Imports: map[string]*packages.Package{"example.com/b/sub": {}}},
+			expectedFound:   true,
+			expectedModules: []string{"example.com/b"},
+		},
+		{
+			name:          "no match on module with same prefix",
+			pkg:           &packages.Package{ID: "pkg4", Imports: map[string]*packages.Package{"example.com/ab": {}}},
+			expectedFound: false,
+		},
+		{
+			name: "no match when module information is available",
+			pkg: &packages.Package{ID: "pkg5", Imports: map[string]*packages.Package{
+				"example.com/a/nested/pkg": {Module: &packages.Module{Path: "example.com/a/nested"}},
+			}},
+			expectedFound: false,
 		},
 	}
 
@@ -39,6 +60,13 @@ func TestModDetector(t *testing.T) {
 			if md.Found() != tc.expectedFound {
 				t.Errorf("expected found to be %v, but got %v", tc.expectedFound, md.Found())
 			}
+
+			if tc.expectedModules == nil {
+				tc.expectedModules = []string{}
+			}
+			if !reflect.DeepEqual(md.Modules(), tc.expectedModules) {
+				t.Errorf("expected modules to be %v, but got %v", tc.expectedModules, md.Modules())
+			}
 		})
 	}
 }
@@ -0,0 +1,219 @@
+package walker
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"go/build"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/brunoluiz/monogo/diskcache"
+	"golang.org/x/tools/go/packages"
+)
+
+// WithCache stores the packages loaded by each walk, and the discovered main packages, so later walkers on
+// the same tree skip loading them. The tree must identify the content of the base path (eg: a git tree hash),
+// as entries are keyed by it plus go.mod, go.sum and the build context. An empty tree disables the cache.
+func WithCache(c *diskcache.Cache, tree string) WithOpt {
+	return func(o *options) {
+		if tree != "" {
+			o.cache, o.tree = c, tree
+		}
+	}
+}
+
+// snapshot contains the packages loaded for a pattern, with enough details to replay walks on them
+type snapshot struct {
+	Roots    []string          `json:"roots"`
+	Packages []snapshotPackage `json:"packages"`
+}
+
+type snapshotPackage struct {
+	ID      string `json:"id"`
+	PkgPath string `json:"pkg_path"`
+	Name    string `json:"name"`
+	// Files are only kept for module packages, relative to the base path
+	CompiledGoFiles []string `json:"compiled_go_files,omitempty"`
+	EmbedFiles      []string `json:"embed_files,omitempty"`
+	// Imports contains the ID of each imported package, by import path
+	Imports map[string]string `json:"imports,omitempty"`
+	Module  *snapshotModule   `json:"module,omitempty"`
+}
+
+type snapshotModule struct {
+	Path    string          `json:"path"`
+	Version string          `json:"version,omitempty"`
+	Main    bool            `json:"main,omitempty"`
+	Replace *snapshotModule `json:"replace,omitempty"`
+}
+
+// cacheKey returns the key for the walker settings, or empty if the cache is disabled
+func (w *Walker) cacheKey(cfg options) (string, error) {
+	if cfg.cache == nil {
+		return "", nil
+	}
+
+	parts := []string{cfg.tree, strconv.FormatBool(cfg.tests), buildContext()}
+	for _, name := range []string{"go.mod", "go.sum"} {
+		data, err := os.ReadFile(filepath.Join(w.basePath, name))
+		if err != nil && !os.IsNotExist(err) {
+			return "", fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		sum := sha256.Sum256(data)
+		parts = append(parts, hex.EncodeToString(sum[:]))
+	}
+	return diskcache.Key(parts...), nil
+}
+
+// loadCached loads the packages matching the pattern from the cache, storing them on misses
+func (w *Walker) loadCached(ctx context.Context, pattern string) ([]*packages.Package, error) {
+	if w.cache == nil {
+		return w.Load(ctx, pattern)
+	}
+
+	key := diskcache.Key(w.key, "walk", pattern)
+	var s snapshot
+	found, err := w.cache.Get(key, &s)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		w.logger.Debug("Loaded packages from cache", "pattern", pattern)
+		return w.restore(s), nil
+	}
+
+	pkgs, err := w.Load(ctx, pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	// Packages with errors fail the walk, so these are not stored
+	if s, ok := w.snapshot(pkgs); ok {
+		if err := w.cache.Put(key, s); err != nil {
+			return nil, err
+		}
+	}
+	return pkgs, nil
+}
+
+func (w *Walker) snapshot(roots []*packages.Package) (snapshot, bool) {
+	s := snapshot{Roots: []string{}, Packages: []snapshotPackage{}}
+	ok := true
+	packages.Visit(roots, nil, func(p *packages.Package) {
+		if len(p.Errors) > 0 {
+			ok = false
+		}
+
+		sp := snapshotPackage{ID: p.ID, PkgPath: p.PkgPath, Name: p.Name, Module: toSnapshotModule(p.Module)}
+		if strings.HasPrefix(p.PkgPath, w.module) {
+			sp.CompiledGoFiles = w.relFiles(p.CompiledGoFiles)
+			sp.EmbedFiles = w.relFiles(p.EmbedFiles)
+		}
+		if len(p.Imports) > 0 {
+			sp.Imports = map[string]string{}
+			for path, imported := range p.Imports {
+				sp.Imports[path] = imported.ID
+			}
+		}
+		s.Packages = append(s.Packages, sp)
+	})
+	for _, root := range roots {
+		s.Roots = append(s.Roots, root.ID)
+	}
+	return s, ok
+}
+
+func (w *Walker) restore(s snapshot) []*packages.Package {
+	byID := map[string]*packages.Package{}
+	for _, sp := range s.Packages {
+		byID[sp.ID] = &packages.Package{
+			ID:              sp.ID,
+			PkgPath:         sp.PkgPath,
+			Name:            sp.Name,
+			CompiledGoFiles: w.absFiles(sp.CompiledGoFiles),
+			EmbedFiles:      w.absFiles(sp.EmbedFiles),
+			Imports:         map[string]*packages.Package{},
+			Module:          fromSnapshotModule(sp.Module),
+		}
+	}
+	for _, sp := range s.Packages {
+		for path, id := range sp.Imports {
+			byID[sp.ID].Imports[path] = byID[id]
+		}
+	}
+
+	roots := []*packages.Package{}
+	for _, id := range s.Roots {
+		roots = append(roots, byID[id])
+	}
+	return roots
+}
+
+func (w *Walker) relFiles(files []string) []string {
+	abs, err := filepath.Abs(w.basePath)
+	if err != nil {
+		return files
+	}
+
+	out := []string{}
+	for _, f := range files {
+		if rel, err := filepath.Rel(abs, f); err == nil {
+			f = filepath.ToSlash(rel)
+		}
+		out = append(out, f)
+	}
+	return out
+}
+
+func (w *Walker) absFiles(files []string) []string {
+	abs, err := filepath.Abs(w.basePath)
+	if err != nil {
+		abs = w.basePath
+	}
+
+	out := []string{}
+	for _, f := range files {
+		out = append(out, filepath.Join(abs, filepath.FromSlash(f)))
+	}
+	return out
+}
+
+func toSnapshotModule(m *packages.Module) *snapshotModule {
+	if m == nil {
+		return nil
+	}
+	return &snapshotModule{Path: m.Path, Version: m.Version, Main: m.Main, Replace: toSnapshotModule(m.Replace)}
+}
+
+func fromSnapshotModule(m *snapshotModule) *packages.Module {
+	if m == nil {
+		return nil
+	}
+	return &packages.Module{Path: m.Path, Version: m.Version, Main: m.Main, Replace: fromSnapshotModule(m.Replace)}
+}
+
+var goVersion = sync.OnceValue(func() string {
+	out, err := exec.Command("go", "env", "GOVERSION").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+})
+
+// buildContext identifies the settings changing which files and packages are loaded
+func buildContext() string {
+	return strings.Join([]string{
+		goVersion(),
+		build.Default.GOOS,
+		build.Default.GOARCH,
+		strconv.FormatBool(build.Default.CgoEnabled),
+		os.Getenv("GOFLAGS"),
+		os.Getenv("GOEXPERIMENT"),
+	}, " ")
+}
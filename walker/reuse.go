@@ -0,0 +1,108 @@
+package walker
+
+import (
+	"context"
+	"path/filepath"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// WithReuse keeps the packages loaded by each walk in memory, so walking the same entry again does not load
+// it again. Changes to the packages must be loaded with Reload.
+func WithReuse(reuse bool) WithOpt {
+	return func(o *options) {
+		o.reuse = reuse
+	}
+}
+
+// loadReused returns the packages loaded for the pattern, if reused, loading and keeping them otherwise.
+// Packages with errors fail the walk, so these are not kept.
+func (w *Walker) loadReused(ctx context.Context, pattern string) ([]*packages.Package, error) {
+	if w.loaded == nil {
+		return w.loadCached(ctx, pattern)
+	}
+
+	w.loadedMu.Lock()
+	defer w.loadedMu.Unlock()
+	if pkgs, ok := w.loaded[pattern]; ok {
+		return pkgs, nil
+	}
+
+	pkgs, err := w.loadCached(ctx, pattern)
+	if err != nil {
+		return nil, err
+	}
+	if !hasErrors(pkgs) {
+		w.loaded[pattern] = pkgs
+	}
+	return pkgs, nil
+}
+
+// Reload loads the packages in the directories, relative to the base path, again and replaces them (and their
+// dependencies) in the reused packages. Other packages are kept as they are, so only the changed ones are loaded.
+// Directories without reused packages are skipped, as no walk reached them. It must not run during walks.
+func (w *Walker) Reload(ctx context.Context, dirs []string) error {
+	if w.loaded == nil {
+		return nil
+	}
+
+	w.loadedMu.Lock()
+	defer w.loadedMu.Unlock()
+
+	base, err := filepath.Abs(w.basePath)
+	if err != nil {
+		return err
+	}
+
+	known := map[string]bool{}
+	for _, roots := range w.loaded {
+		packages.Visit(roots, nil, func(p *packages.Package) {
+			for _, f := range p.CompiledGoFiles {
+				known[filepath.Dir(f)] = true
+			}
+		})
+	}
+
+	patterns := []string{}
+	for _, dir := range dirs {
+		if known[filepath.Join(base, dir)] {
+			patterns = append(patterns, "./"+filepath.ToSlash(dir))
+		}
+	}
+	if len(patterns) == 0 {
+		return nil
+	}
+
+	fresh, err := w.load(ctx, patterns...)
+	if err != nil {
+		return err
+	}
+
+	byID := map[string]*packages.Package{}
+	packages.Visit(fresh, nil, func(p *packages.Package) { byID[p.ID] = p })
+
+	// Imports are replaced after visiting them, so each reused package is only visited once
+	for _, roots := range w.loaded {
+		packages.Visit(roots, nil, func(p *packages.Package) {
+			for path, imported := range p.Imports {
+				if reloaded, ok := byID[imported.ID]; ok {
+					p.Imports[path] = reloaded
+				}
+			}
+		})
+		for i, root := range roots {
+			if reloaded, ok := byID[root.ID]; ok {
+				roots[i] = reloaded
+			}
+		}
+	}
+	return nil
+}
+
+func hasErrors(pkgs []*packages.Package) bool {
+	found := false
+	packages.Visit(pkgs, nil, func(p *packages.Package) {
+		found = found || len(p.Errors) > 0
+	})
+	return found
+}
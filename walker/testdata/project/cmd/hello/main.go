@@ -0,0 +1,7 @@
+package main
+
+import "test/project/pkgA"
+
+func main() {
+	pkgA.PkgA()
+}
@@ -0,0 +1,7 @@
+package pkgB
+
+import "testing"
+
+func TestPkgB(t *testing.T) {
+	PkgB()
+}
@@ -3,38 +3,77 @@ package walker
 import (
 	"context"
 	"fmt"
+	"io/fs"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 
+	"github.com/brunoluiz/monogo/diskcache"
 	"golang.org/x/mod/modfile"
 	"golang.org/x/tools/go/packages"
 )
 
 type Walker struct {
-	cache interface {
-		get(k string) (*packages.Package, bool)
-		set(k string, p *packages.Package)
-	}
 	logger   *slog.Logger
 	basePath string
 	module   string
+	tests    bool
+	cache    *diskcache.Cache
+	key      string
+	// loaded contains the packages loaded for each pattern, if reused between walks
+	loaded   map[string][]*packages.Package
+	loadedMu sync.Mutex
+}
+
+type WithOpt func(*options)
+
+type options struct {
+	tests bool
+	cache *diskcache.Cache
+	tree  string
+	reuse bool
+}
+
+// WithTests loads test packages (and their dependencies) for each walked entry
+func WithTests(tests bool) WithOpt {
+	return func(o *options) {
+		o.tests = tests
+	}
 }
 
-func New(basePath string, logger *slog.Logger) (*Walker, error) {
+func New(basePath string, logger *slog.Logger, opts ...WithOpt) (*Walker, error) {
+	cfg := options{}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
 	module, err := getModuleName(basePath)
 	if err != nil {
 		return nil, fmt.Errorf("base path might not be a module: %w", err)
 	}
 
-	return &Walker{
-		cache:    newCache(),
+	w := &Walker{
 		logger:   logger,
 		basePath: basePath,
 		module:   module,
-	}, nil
+		tests:    cfg.tests,
+		cache:    cfg.cache,
+	}
+	if cfg.reuse {
+		w.loaded = map[string][]*packages.Package{}
+	}
+	if w.key, err = w.cacheKey(cfg); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Module returns the module path of the base path
+func (w *Walker) Module() string {
+	return w.module
 }
 
 type Hook interface {
@@ -46,6 +85,115 @@ func (w *Walker) Walk(ctx context.Context, entry string, hooks ...Hook) error {
 	return w.walk(ctx, entry, hooks...)
 }
 
+// Load loads the packages matching the pattern, including their dependencies, without walking them.
+// Unlike Walk, the pattern is used as is, so it can refer to packages outside the module.
+func (w *Walker) Load(ctx context.Context, pattern string) ([]*packages.Package, error) {
+	return w.load(ctx, pattern)
+}
+
+func (w *Walker) load(ctx context.Context, patterns ...string) ([]*packages.Package, error) {
+	pkgs, err := packages.Load(&packages.Config{
+		Context: ctx,
+		Mode:    packages.NeedImports | packages.NeedCompiledGoFiles | packages.NeedDeps | packages.NeedEmbedFiles | packages.NeedEmbedPatterns | packages.NeedName | packages.NeedModule,
+		Dir:     w.basePath,
+		Tests:   w.tests,
+	}, patterns...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load packages: %w", err)
+	}
+	return pkgs, nil
+}
+
+// Exists reports whether the entry, relative to the base path, is a directory containing non-test Go files.
+// Entries ending with `/...` exist if any directory within them does, following the go tool rules.
+func (w *Walker) Exists(entry string) bool {
+	dir, recursive := strings.CutSuffix(filepath.ToSlash(entry), "...")
+	if !recursive {
+		return hasGoFiles(filepath.Join(w.basePath, entry))
+	}
+
+	found := false
+	_ = filepath.WalkDir(filepath.Join(w.basePath, dir), func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		if name := d.Name(); path != filepath.Join(w.basePath, dir) &&
+			(name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+			return filepath.SkipDir
+		}
+		if hasGoFiles(path) {
+			found = true
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	return found
+}
+
+// hasGoFiles reports whether the directory contains non-test Go files
+func hasGoFiles(dir string) bool {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return false
+	}
+	for _, f := range files {
+		if !strings.HasSuffix(f, "_test.go") {
+			return true
+		}
+	}
+	return false
+}
+
+// Discover lists the main packages within the module, as slash separated paths relative to the
+// base path (eg: cmd/app). Packages under testdata, or directories starting with `.` or `_`, are ignored.
+func (w *Walker) Discover(ctx context.Context) ([]string, error) {
+	if w.cache == nil {
+		return w.discover(ctx)
+	}
+
+	key := diskcache.Key(w.key, "discover")
+	mains := []string{}
+	found, err := w.cache.Get(key, &mains)
+	if err != nil || found {
+		return mains, err
+	}
+
+	if mains, err = w.discover(ctx); err != nil {
+		return nil, err
+	}
+	return mains, w.cache.Put(key, mains)
+}
+
+func (w *Walker) discover(ctx context.Context) ([]string, error) {
+	pkgs, err := packages.Load(&packages.Config{
+		Context: ctx,
+		Mode:    packages.NeedName,
+		Dir:     w.basePath,
+	}, "./...")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load packages: %w", err)
+	}
+
+	mains := []string{}
+	for _, pkg := range pkgs {
+		if pkg.Name != "main" {
+			continue
+		}
+
+		rel, ok := strings.CutPrefix(pkg.PkgPath, w.module)
+		if !ok {
+			continue
+		}
+		if rel = strings.TrimPrefix(rel, "/"); rel == "" {
+			rel = "."
+		}
+		mains = append(mains, rel)
+	}
+
+	sort.Strings(mains)
+	return mains, nil
+}
+
 func (w *Walker) walk(ctx context.Context, entry string, hooks ...Hook) error {
 	select {
 	case <-ctx.Done():
@@ -54,17 +202,16 @@ func (w *Walker) walk(ctx context.Context, entry string, hooks ...Hook) error {
 		// Load all packages in the codebase
 		// NOTE: The pattern (value given by `entry`) must be prefixed with `./` as otherwise it might end up with a package name
 		// This becomes a problem when the user configures entrypoints as `cmd/bla` instead of `./cmd/bla`
-		pkgs, err := packages.Load(&packages.Config{
-			Mode: packages.NeedImports | packages.NeedCompiledGoFiles | packages.NeedDeps | packages.NeedEmbedFiles | packages.NeedEmbedPatterns | packages.NeedName,
-			Dir:  w.basePath,
-		}, "./"+entry)
+		pkgs, err := w.loadReused(ctx, "./"+entry)
 		if err != nil {
-			return fmt.Errorf("failed to load packages: %w", err)
+			return err
 		}
 
-		// Iterate through all packages to find dependencies
+		// Iterate through all packages to find dependencies. Visited packages are tracked per walk, as
+		// walks for different entries might run concurrently and each must reach all of its packages.
+		visited := newCache()
 		for _, pkg := range pkgs {
-			if err := w.handlePackage(ctx, pkg, hooks...); err != nil {
+			if err := w.handlePackage(ctx, pkg, visited, hooks...); err != nil {
 				return err
 			}
 		}
@@ -76,6 +223,7 @@ func (w *Walker) walk(ctx context.Context, entry string, hooks ...Hook) error {
 func (w *Walker) handlePackage(
 	ctx context.Context,
 	pkg *packages.Package,
+	visited *cache,
 	hooks ...Hook,
 ) error {
 	if len(pkg.Errors) != 0 {
@@ -92,17 +240,17 @@ func (w *Walker) handlePackage(
 		}
 	}
 
-	// NOTE: Not sure if this cache is correctly placed yet
-	if _, found := w.cache.get(pkg.PkgPath); found {
+	// The ID is used, as test variants share the same package path (eg: `pkg` and `pkg [pkg.test]`)
+	if _, found := visited.get(pkg.ID); found {
 		return nil
 	}
 
 	for _, imported := range pkg.Imports {
-		if err := w.handlePackage(ctx, imported, hooks...); err != nil {
+		if err := w.handlePackage(ctx, imported, visited, hooks...); err != nil {
 			return err
 		}
 	}
-	w.cache.set(pkg.PkgPath, pkg)
+	visited.set(pkg.ID, pkg)
 
 	return nil
 }
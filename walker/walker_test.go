@@ -4,10 +4,12 @@ import (
 	"context"
 	"log/slog"
 	"os"
+	"path/filepath"
 	"reflect"
 	"sort"
 	"testing"
 
+	"github.com/brunoluiz/monogo/diskcache"
 	"github.com/brunoluiz/monogo/walker"
 	"golang.org/x/tools/go/packages"
 )
@@ -25,6 +27,7 @@ func TestWalker_Walk(t *testing.T) {
 	testCases := []struct {
 		name             string
 		entry            string
+		opts             []walker.WithOpt
 		expectedPkgPaths []string
 	}{
 		{
@@ -42,12 +45,18 @@ func TestWalker_Walk(t *testing.T) {
 			entry:            "./pkgB",
 			expectedPkgPaths: []string{"test/project/pkgB"},
 		},
+		{
+			name:             "entry from pkgB with tests",
+			entry:            "./pkgB",
+			opts:             []walker.WithOpt{walker.WithTests(true)},
+			expectedPkgPaths: []string{"test/project/pkgB", "test/project/pkgB", "test/project/pkgB", "test/project/pkgB.test"},
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
-			w, err := walker.New("./testdata/project", logger)
+			w, err := walker.New("./testdata/project", logger, tc.opts...)
 			if err != nil {
 				t.Fatalf("failed to create walker: %s", err)
 			}
@@ -72,3 +81,169 @@ func TestWalker_Walk(t *testing.T) {
 		})
 	}
 }
+
+func TestWalker_Walk_Twice(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	w, err := walker.New("./testdata/project", logger)
+	if err != nil {
+		t.Fatalf("failed to create walker: %s", err)
+	}
+
+	// Each walk reaches all of its packages, regardless of the ones visited by previous walks
+	expected := []string{"test/project/pkgA", "test/project/pkgB", "test/project/pkgC"}
+	for i := 1; i <= 2; i++ {
+		hook := &mockHook{}
+		if err := w.Walk(context.Background(), "pkgC", hook); err != nil {
+			t.Fatalf("failed to walk: %s", err)
+		}
+
+		var gotPkgPaths []string
+		for _, p := range hook.calledWith {
+			gotPkgPaths = append(gotPkgPaths, p.PkgPath)
+		}
+		sort.Strings(gotPkgPaths)
+
+		if !reflect.DeepEqual(gotPkgPaths, expected) {
+			t.Errorf("unexpected packages on walk %d, got %+v, want %+v", i, gotPkgPaths, expected)
+		}
+	}
+}
+
+func TestWalker_Walk_Cache(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.CopyFS(dir, os.DirFS("./testdata/project")); err != nil {
+		t.Fatalf("failed to copy project: %s", err)
+	}
+	c, err := diskcache.New(filepath.Join(t.TempDir(), "cache"))
+	if err != nil {
+		t.Fatalf("failed to create cache: %s", err)
+	}
+
+	walk := func(tree string) ([]string, error) {
+		logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+		w, err := walker.New(dir, logger, walker.WithCache(c, tree))
+		if err != nil {
+			t.Fatalf("failed to create walker: %s", err)
+		}
+
+		hook := &mockHook{}
+		if err := w.Walk(context.Background(), "pkgC", hook); err != nil {
+			return nil, err
+		}
+
+		var got []string
+		for _, p := range hook.calledWith {
+			for _, f := range p.CompiledGoFiles {
+				got = append(got, p.PkgPath+":"+filepath.Base(f))
+			}
+			if !p.Module.Main {
+				t.Errorf("expected %s to be in the main module", p.PkgPath)
+			}
+		}
+		sort.Strings(got)
+		return got, nil
+	}
+
+	expected := []string{"test/project/pkgA:a.go", "test/project/pkgB:b.go", "test/project/pkgC:c.go"}
+	if got, err := walk("tree-1"); err != nil || !reflect.DeepEqual(got, expected) {
+		t.Fatalf("unexpected cold walk, got %+v (%v), want %+v", got, err, expected)
+	}
+
+	// Cached trees are not loaded again, so the deleted package is still reported
+	if err := os.Remove(filepath.Join(dir, "pkgA", "a.go")); err != nil {
+		t.Fatalf("failed to remove file: %s", err)
+	}
+	if got, err := walk("tree-1"); err != nil || !reflect.DeepEqual(got, expected) {
+		t.Errorf("unexpected warm walk, got %+v (%v), want %+v", got, err, expected)
+	}
+	if _, err := walk("tree-2"); err == nil {
+		t.Errorf("expected the walk on another tree to load the broken package")
+	}
+}
+
+func TestWalker_Walk_Reuse(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.CopyFS(dir, os.DirFS("./testdata/project")); err != nil {
+		t.Fatalf("failed to copy project: %s", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	w, err := walker.New(dir, logger, walker.WithReuse(true))
+	if err != nil {
+		t.Fatalf("failed to create walker: %s", err)
+	}
+
+	walk := func() []string {
+		hook := &mockHook{}
+		if err := w.Walk(context.Background(), "pkgC", hook); err != nil {
+			t.Fatalf("failed to walk: %s", err)
+		}
+
+		var got []string
+		for _, p := range hook.calledWith {
+			got = append(got, p.PkgPath)
+		}
+		sort.Strings(got)
+		return got
+	}
+
+	all := []string{"test/project/pkgA", "test/project/pkgB", "test/project/pkgC"}
+	if got := walk(); !reflect.DeepEqual(got, all) {
+		t.Fatalf("unexpected walk, got %+v, want %+v", got, all)
+	}
+
+	// pkgC stops importing pkgA, which is only seen once reloaded
+	c := "package pkgC\n\nimport \"test/project/pkgB\"\n\nfunc PkgC() {\n\tpkgB.PkgB()\n}\n"
+	if err := os.WriteFile(filepath.Join(dir, "pkgC", "c.go"), []byte(c), 0o600); err != nil {
+		t.Fatalf("failed to write file: %s", err)
+	}
+	if got := walk(); !reflect.DeepEqual(got, all) {
+		t.Errorf("unexpected reused walk, got %+v, want %+v", got, all)
+	}
+
+	if err := w.Reload(context.Background(), []string{"pkgC", "cmd/hello"}); err != nil {
+		t.Fatalf("failed to reload: %s", err)
+	}
+	if got, expected := walk(), []string{"test/project/pkgB", "test/project/pkgC"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("unexpected reloaded walk, got %+v, want %+v", got, expected)
+	}
+}
+
+func TestWalker_Discover(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	w, err := walker.New("./testdata/project", logger)
+	if err != nil {
+		t.Fatalf("failed to create walker: %s", err)
+	}
+
+	mains, err := w.Discover(context.Background())
+	if err != nil {
+		t.Fatalf("failed to discover: %s", err)
+	}
+
+	if expected := []string{"cmd/hello"}; !reflect.DeepEqual(mains, expected) {
+		t.Errorf("unexpected main packages, got %+v, want %+v", mains, expected)
+	}
+}
+
+func TestWalker_Exists(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	w, err := walker.New("./testdata/project", logger)
+	if err != nil {
+		t.Fatalf("failed to create walker: %s", err)
+	}
+
+	for entry, expected := range map[string]bool{
+		"cmd/hello":       true,
+		"./pkgB":          true,
+		"cmd":             false,
+		"cmd/missing":     false,
+		"cmd/...":         true,
+		"./...":           true,
+		"cmd/missing/...": false,
+	} {
+		if got := w.Exists(entry); got != expected {
+			t.Errorf("expected %s to exist: %v, but got %v", entry, expected, got)
+		}
+	}
+}
@@ -0,0 +1,365 @@
+package monogo
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/brunoluiz/monogo/glob"
+	"github.com/brunoluiz/monogo/mod"
+	"github.com/brunoluiz/monogo/walker"
+	"github.com/brunoluiz/monogo/walker/hook"
+	"github.com/samber/lo"
+	"golang.org/x/mod/modfile"
+)
+
+// WatchEvent contains the entrypoints affected by a set of changed files
+type WatchEvent struct {
+	Time        time.Time             `json:"time"`
+	Files       []string              `json:"files"`
+	Entrypoints []DetectEntrypointRes `json:"entrypoints"`
+	Waves       [][]string            `json:"waves"`
+}
+
+// watchIndex contains the files of each entrypoint, as walked on the last load, go.mod as of the last poll
+// and the files matching the global triggers and inputs, along with the directories walked to glob them
+type watchIndex struct {
+	w        *walker.Walker
+	mod      *modfile.File
+	targets  []string
+	files    map[string][]string
+	globbed  []string
+	globDirs map[string]time.Time
+}
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// Watch polls the tree in the path every interval and calls fn with the entrypoints affected by the files
+// changed since the previous poll, until the context is done. If git is set, fn is first called with the
+// entrypoints affected by the worktree changes compared to the base ref.
+//
+// Only the files of each entrypoint (Go and embedded files), new Go files in their packages, go.mod, go.sum,
+// extra inputs and global triggers are polled. Triggers and inputs are globbed again only once the
+// directories walked to glob them change. Changes to go.mod are compared with its previous version, so only
+// the entrypoints importing changed modules are reported, as well as go version, toolchain and godebug
+// changes (following the policies and scope set). Entrypoints are walked once, and walked again only if
+// affected. Loaded packages are kept between polls, so only the packages with changed files are loaded
+// again (or all of them, once go.mod or go.sum change). Walks failing (eg: due to syntax errors) keep the
+// previous files.
+func (r *Detector) Watch(ctx context.Context, interval time.Duration, fn func(WatchEvent) error) error {
+	w, err := r.watchWalker()
+	if err != nil {
+		return err
+	}
+
+	_, modFile, err := mod.Get(mod.WithModDir(r.Path))
+	if err != nil {
+		return fmt.Errorf("failed to get go.mod: %w", err)
+	}
+
+	idx := &watchIndex{w: w, mod: modFile, files: map[string][]string{}}
+	if err := r.watchTargets(ctx, idx); err != nil {
+		return err
+	}
+	r.watchWalk(ctx, idx, idx.targets)
+
+	if r.Git != nil {
+		files, err := r.Git.WorktreeChanges(r.BaseRef)
+		if err != nil {
+			return fmt.Errorf("failed to get worktree changes: %w", err)
+		}
+		res, err := r.Affected(ctx, files)
+		if err != nil {
+			return err
+		}
+		event := WatchEvent{
+			Time:        time.Now(),
+			Files:       files,
+			Entrypoints: slices.Concat(res.Entrypoints, res.Libraries),
+			Waves:       res.Waves,
+		}
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+
+	state, err := r.watchState(idx)
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		next, err := r.watchState(idx)
+		if err != nil {
+			return err
+		}
+		changes := changedStates(state, next)
+		state = next
+		if _, changes = glob.Filter(r.Ignore, changes); len(changes) == 0 {
+			continue
+		}
+
+		results := r.watchAffected(ctx, idx, changes)
+		if len(results) == 0 {
+			continue
+		}
+
+		// Only affected entrypoints are walked again, as the files of the others did not change
+		if err := r.watchReload(ctx, idx, changes); err != nil {
+			r.Logger.Warn("failed to reload packages", "error", err)
+		}
+		if slices.ContainsFunc(changes, isGoFile) {
+			if err := r.watchTargets(ctx, idx); err != nil {
+				r.Logger.Warn("failed to get entrypoints", "error", err)
+			}
+		}
+		r.watchWalk(ctx, idx, lo.Map(results, func(res DetectEntrypointRes, _ int) string { return res.Path }))
+		listed, err := r.watchState(idx)
+		if err != nil {
+			return err
+		}
+
+		// The walk might list other files, but the known ones keep their state from before the walk, so
+		// edits made while walking are reported on the next poll
+		for file := range state {
+			if _, ok := listed[file]; !ok {
+				delete(state, file)
+			}
+		}
+		for file, s := range listed {
+			if _, ok := state[file]; !ok {
+				state[file] = s
+			}
+		}
+
+		results, waves, err := r.dependsOn(results, idx.targets)
+		if err != nil {
+			return err
+		}
+		r.populateMetadata(results)
+		if err := fn(WatchEvent{Time: time.Now(), Files: changes, Entrypoints: results, Waves: waves}); err != nil {
+			return err
+		}
+	}
+}
+
+// watchWalker returns a walker keeping the loaded packages between walks
+func (r *Detector) watchWalker() (*walker.Walker, error) {
+	return walker.New(r.Path, r.Logger.WithGroup("walker:watch"), walker.WithReuse(true))
+}
+
+// watchReload loads the packages containing the changed files again. Changes to go.mod or go.sum might
+// change any package, so all of them are dropped and loaded again by the next walks.
+func (r *Detector) watchReload(ctx context.Context, idx *watchIndex, changes []string) error {
+	if slices.Contains(changes, "go.mod") || slices.Contains(changes, "go.sum") {
+		w, err := r.watchWalker()
+		if err != nil {
+			return err
+		}
+		idx.w = w
+		return nil
+	}
+
+	dirs := lo.Uniq(lo.Map(changes, func(file string, _ int) string { return path.Dir(file) }))
+	return idx.w.Reload(ctx, dirs)
+}
+
+// watchTargets sets the entrypoints and libraries, walking the ones not walked yet
+func (r *Detector) watchTargets(ctx context.Context, idx *watchIndex) error {
+	targets, err := r.targets(ctx, idx.w)
+	if err != nil {
+		return err
+	}
+
+	added := lo.Filter(targets, func(entry string, _ int) bool { _, ok := idx.files[entry]; return !ok })
+	idx.targets = targets
+	r.watchWalk(ctx, idx, added)
+	return nil
+}
+
+// watchWalk walks the entrypoints, updating their files. Failed walks are logged, keeping the previous files.
+func (r *Detector) watchWalk(ctx context.Context, idx *watchIndex, entrypoints []string) {
+	for _, entry := range entrypoints {
+		listerHook := hook.NewLister()
+		if err := idx.w.Walk(ctx, entry, listerHook); err != nil {
+			r.Logger.Warn("failed to walk entrypoint", "entrypoint", entry, "error", err)
+			if _, ok := idx.files[entry]; !ok {
+				idx.files[entry] = []string{}
+			}
+			continue
+		}
+		idx.files[entry] = relPaths(r.Path, listerHook.Files())
+	}
+}
+
+// watchAffected returns the entrypoints affected by the changed files. Changed modules are matched against
+// the packages loaded before the change, as the entrypoints are only walked again if affected.
+func (r *Detector) watchAffected(ctx context.Context, idx *watchIndex, changes []string) []DetectEntrypointRes {
+	triggers, _ := glob.Filter(r.Triggers, changes)
+	modDiff := r.watchModDiff(idx, changes)
+	globalReasons := r.globalReasons(modDiff)
+	modules := modDiff.Packages.All()
+
+	results := []DetectEntrypointRes{}
+	for _, entry := range idx.targets {
+		ignored, entryChanges := glob.Filter(globsFor(r.EntrypointIgnore, entry), changes)
+		dirs := lo.Uniq(lo.Map(idx.files[entry], func(file string, _ int) string { return path.Dir(file) }))
+
+		reasons := []ChangeReason{}
+		if lo.SomeBy(entryChanges, func(file string) bool {
+			return slices.Contains(idx.files[entry], file) || (isGoFile(file) && slices.Contains(dirs, path.Dir(file)))
+		}) {
+			reasons = append(reasons, ChangedFilesReason)
+		}
+		dependencies := []DetectDependencyRes{}
+		if len(modules) > 0 {
+			modHook := hook.NewModDetector(modules)
+			if err := idx.w.Walk(ctx, entry, modHook); err != nil {
+				r.Logger.Warn("failed to walk entrypoint", "entrypoint", entry, "error", err)
+			} else if modHook.Found() {
+				reasons = append(reasons, DependenciesChangedReason)
+				dependencies = dependenciesRes(modDiff, modHook.Modules())
+			}
+		}
+		reasons = append(reasons, globalReasons...)
+		reasons = append(reasons, r.godebugReasons(entry, modDiff)...)
+		if len(triggers) > 0 {
+			reasons = append(reasons, GlobalTriggerReason)
+		}
+		reasons = append(reasons, r.inputsReasons(r.Inputs, entry, entryChanges)...)
+
+		if len(reasons) > 0 {
+			results = append(results, DetectEntrypointRes{
+				Path:         entry,
+				Changed:      true,
+				Reasons:      reasons,
+				Dependencies: dependencies,
+				Inputs:       []string{},
+				Ignored:      ignored,
+				Triggers:     triggers,
+			})
+		}
+	}
+	return results
+}
+
+// watchModDiff returns the changes of go.mod since the last poll. Changes to go.sum alone are not reported,
+// as checksums only change along with the required modules.
+func (r *Detector) watchModDiff(idx *watchIndex, changes []string) mod.Output {
+	if !slices.Contains(changes, "go.mod") {
+		return mod.Output{}
+	}
+
+	_, modFile, err := mod.Get(mod.WithModDir(r.Path))
+	if err != nil {
+		r.Logger.Warn("failed to get go.mod", "error", err)
+		return mod.Output{}
+	}
+
+	modDiff := mod.Diff(idx.mod, modFile)
+	idx.mod = modFile
+	return modDiff
+}
+
+// watchState returns the state of the polled files, by repository relative path
+func (r *Detector) watchState(idx *watchIndex) (map[string]fileState, error) {
+	files := []string{"go.mod", "go.sum"}
+	dirs := []string{}
+	for _, entryFiles := range idx.files {
+		files = append(files, entryFiles...)
+		dirs = append(dirs, lo.Map(entryFiles, func(file string, _ int) string { return path.Dir(file) })...)
+	}
+
+	// New Go files might be added to the packages of the entrypoints
+	for _, dir := range lo.Uniq(dirs) {
+		entries, err := os.ReadDir(filepath.Join(r.Path, dir))
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			if !e.IsDir() && isGoFile(e.Name()) {
+				files = append(files, path.Join(dir, e.Name()))
+			}
+		}
+	}
+
+	matched, err := r.watchGlob(idx)
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, matched...)
+
+	state := map[string]fileState{}
+	for _, file := range lo.Uniq(files) {
+		if info, err := os.Stat(filepath.Join(r.Path, file)); err == nil {
+			state[file] = fileState{modTime: info.ModTime(), size: info.Size()}
+		}
+	}
+	return state, nil
+}
+
+// watchGlob returns the files matching the global triggers and inputs. The tree is only globbed again once
+// any of the directories walked before changes, as files are created, deleted or renamed only then.
+func (r *Detector) watchGlob(idx *watchIndex) ([]string, error) {
+	if idx.globDirs != nil && !dirsChanged(r.Path, idx.globDirs) {
+		return idx.globbed, nil
+	}
+
+	patterns := slices.Concat(r.Triggers, lo.Flatten(lo.Values(r.Inputs)))
+	matched, dirs, err := glob.GlobDirs(r.Path, patterns)
+	if err != nil {
+		return nil, err
+	}
+	idx.globbed, idx.globDirs = matched, dirs
+	return matched, nil
+}
+
+// dirsChanged returns true if any of the directories was modified or removed
+func dirsChanged(root string, dirs map[string]time.Time) bool {
+	for dir, modTime := range dirs {
+		info, err := os.Stat(filepath.Join(root, dir))
+		if err != nil || !info.ModTime().Equal(modTime) {
+			return true
+		}
+	}
+	return false
+}
+
+// changedStates returns the sorted files created, updated or deleted between the states
+func changedStates(before, after map[string]fileState) []string {
+	changes := []string{}
+	for file, s := range after {
+		if prev, ok := before[file]; !ok || prev != s {
+			changes = append(changes, file)
+		}
+	}
+	for file := range before {
+		if _, ok := after[file]; !ok {
+			changes = append(changes, file)
+		}
+	}
+
+	slices.Sort(changes)
+	return changes
+}
+
+// isGoFile returns true for Go files part of the walked packages, which do not include tests
+func isGoFile(file string) bool {
+	return strings.HasSuffix(file, ".go") && !strings.HasSuffix(file, "_test.go")
+}
@@ -0,0 +1,118 @@
+package monogo_test
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brunoluiz/monogo"
+	xgit "github.com/brunoluiz/monogo/git"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetector_Watch(t *testing.T) {
+	tmpDir, w, _ := setupTestRepo(t)
+	commitFile(t, w, "pkg/pkgA/extra.go", "package pkgA\n")
+
+	g, err := xgit.New(xgit.WithPath(tmpDir))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events := make(chan monogo.WatchEvent)
+	errs := make(chan error, 1)
+	d := monogo.NewDetector([]string{"cmd/app1", "cmd/app2", "cmd/app3"}, slog.Default(), g,
+		monogo.WithPath(tmpDir), monogo.WithIgnore([]string{"**/*.md"}),
+		monogo.WithTriggers([]string{"config/**/*.yaml"}),
+	)
+	go func() {
+		errs <- d.Watch(ctx, 50*time.Millisecond, func(event monogo.WatchEvent) error {
+			events <- event
+			return nil
+		})
+	}()
+
+	next := func() monogo.WatchEvent {
+		t.Helper()
+		select {
+		case event := <-events:
+			return event
+		case err := <-errs:
+			t.Fatalf("watch stopped: %v", err)
+		case <-time.After(30 * time.Second):
+			t.Fatal("timed out waiting for watch event")
+		}
+		return monogo.WatchEvent{}
+	}
+
+	// The first event reports the changes compared to the base ref
+	event := next()
+	require.Equal(t, []string{"pkg/pkgA/extra.go"}, event.Files)
+	require.Equal(t, []string{"app1", "app3"}, entrypointPaths(event.Entrypoints))
+
+	// Ignored files and tests do not trigger events, so the next one only contains the Go file
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "pkg/pkgB/README.md"), []byte("docs"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "pkg/pkgB/b_test.go"), []byte("package pkgB\n"), 0o600))
+	appendFile(t, filepath.Join(tmpDir, "pkg/pkgB/b.go"), "\n// changed\n")
+	event = next()
+	require.Equal(t, []string{"pkg/pkgB/b.go"}, event.Files)
+	require.Equal(t, []string{"app2", "app3"}, entrypointPaths(event.Entrypoints))
+	require.Equal(t, []monogo.ChangeReason{monogo.ChangedFilesReason}, event.Entrypoints[0].Reasons)
+
+	// New files in the packages of the entrypoints are picked up as well
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "pkg/shared/extra.go"), []byte("package shared\n"), 0o600))
+	event = next()
+	require.Equal(t, []string{"pkg/shared/extra.go"}, event.Files)
+	require.Equal(t, []string{"app1", "app2", "app3"}, entrypointPaths(event.Entrypoints))
+
+	// go.mod changes not affecting modules or versions do not mark any entrypoint, whether they are polled
+	// alone or along with the Go file
+	appendFile(t, filepath.Join(tmpDir, "go.mod"), "\n// comment\n")
+	appendFile(t, filepath.Join(tmpDir, "pkg/pkgB/b.go"), "\n// changed again\n")
+	event = next()
+	require.Equal(t, []string{"app2", "app3"}, entrypointPaths(event.Entrypoints))
+	for _, entry := range event.Entrypoints {
+		require.Equal(t, []monogo.ChangeReason{monogo.ChangedFilesReason}, entry.Reasons)
+	}
+
+	// Changed modules only mark the entrypoints importing them
+	modPath := filepath.Join(tmpDir, "go.mod")
+	content, err := os.ReadFile(modPath)
+	require.NoError(t, err)
+	content = []byte(strings.Replace(string(content), "go.uber.org/zap v1.27.0", "go.uber.org/zap v1.27.1", 1))
+	require.NoError(t, os.WriteFile(modPath, content, 0o600))
+	event = next()
+	require.Equal(t, []string{"app1", "app2", "app3"}, entrypointPaths(event.Entrypoints))
+	for _, entry := range event.Entrypoints {
+		require.Equal(t, []monogo.ChangeReason{monogo.DependenciesChangedReason}, entry.Reasons)
+		require.Equal(t, []string{"go.uber.org/zap"}, lo.Map(entry.Dependencies,
+			func(dep monogo.DetectDependencyRes, _ int) string { return dep.Path }))
+	}
+
+	// Triggers created in new directories are globbed once their parents change
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "config/app"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "config/app/app.yaml"), []byte("a: 1\n"), 0o600))
+	event = next()
+	require.Equal(t, []string{"config/app/app.yaml"}, event.Files)
+	require.Equal(t, []string{"app1", "app2", "app3"}, entrypointPaths(event.Entrypoints))
+	require.Equal(t, []monogo.ChangeReason{monogo.GlobalTriggerReason}, event.Entrypoints[0].Reasons)
+
+	cancel()
+	require.NoError(t, <-errs)
+}
+
+func appendFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	require.NoError(t, err)
+	defer f.Close()
+	_, err = f.WriteString(content)
+	require.NoError(t, err)
+}
@@ -0,0 +1,63 @@
+package monogo
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/brunoluiz/monogo/walker"
+	"github.com/brunoluiz/monogo/walker/hook"
+	"github.com/samber/lo"
+)
+
+type WhoUsesRes struct {
+	Target      string                 `json:"target"`
+	Hash        string                 `json:"hash"`
+	Ref         string                 `json:"ref"`
+	Entrypoints []WhoUsesEntrypointRes `json:"entrypoints"`
+}
+
+type WhoUsesEntrypointRes struct {
+	Path string `json:"path"`
+	// Chains contains the shortest import chain, from the entrypoint, for each package matching the target
+	Chains [][]string `json:"chains"`
+}
+
+// WhoUses lists the entrypoints importing the target at the given ref. The target
+// can be either a package or a module path.
+func (r *Detector) WhoUses(ctx context.Context, ref, target string) (WhoUsesRes, error) {
+	hash, refName, err := r.Git.Ref(ref)
+	if err != nil {
+		return WhoUsesRes{}, fmt.Errorf("failed to get ref: %w", err)
+	}
+
+	chains := map[string][][]string{}
+	rw := sync.RWMutex{}
+	entrypoints, err := r.walkEntrypoints(ctx, ref, nil, nil, func(ctx context.Context, w *walker.Walker, entry string) error {
+		finderHook := hook.NewImportFinder(target)
+		if err := w.Walk(ctx, entry, finderHook); err != nil {
+			return err
+		}
+
+		// Write operations to shared memory below
+		rw.Lock()
+		defer rw.Unlock()
+		if finderHook.Found() {
+			chains[entry] = finderHook.Chains()
+		}
+		return nil
+	})
+	if err != nil {
+		return WhoUsesRes{}, fmt.Errorf("failure while walking entrypoints: %w", err)
+	}
+
+	return WhoUsesRes{
+		Target: target,
+		Hash:   hash,
+		Ref:    refName,
+		Entrypoints: lo.FilterMap(entrypoints, func(entry string, _ int) (WhoUsesEntrypointRes, bool) {
+			c, ok := chains[entry]
+			return WhoUsesEntrypointRes{Path: entry, Chains: c}, ok
+		}),
+	}, nil
+}
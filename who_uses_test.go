@@ -0,0 +1,56 @@
+package monogo_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/brunoluiz/monogo"
+	xgit "github.com/brunoluiz/monogo/git"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetector_WhoUses(t *testing.T) {
+	tmpDir, _, b := setupTestRepo(t)
+
+	g, err := xgit.New(xgit.WithPath(tmpDir))
+	require.NoError(t, err)
+	d := monogo.NewDetector([]string{"cmd/app1", "cmd/app2", "cmd/app3"}, slog.Default(), g,
+		monogo.WithPath(tmpDir),
+	)
+
+	t.Run("module", func(t *testing.T) {
+		res, err := d.WhoUses(context.Background(), string(b), "go.uber.org/multierr")
+		require.NoError(t, err)
+		require.Len(t, res.Entrypoints, 3)
+		require.Equal(t, monogo.WhoUsesEntrypointRes{
+			Path: "cmd/app1",
+			Chains: [][]string{
+				{"test-project/cmd/app1", "test-project/pkg/shared", "go.uber.org/zap", "go.uber.org/multierr"},
+			},
+		}, res.Entrypoints[0])
+	})
+
+	t.Run("package", func(t *testing.T) {
+		res, err := d.WhoUses(context.Background(), string(b), "test-project/pkg/pkgA")
+		require.NoError(t, err)
+		require.Equal(t, []monogo.WhoUsesEntrypointRes{
+			{Path: "cmd/app1", Chains: [][]string{{"test-project/cmd/app1", "test-project/pkg/pkgA"}}},
+			{Path: "cmd/app3", Chains: [][]string{{"test-project/cmd/app3", "test-project/pkg/pkgA"}}},
+		}, res.Entrypoints)
+	})
+
+	t.Run("discover", func(t *testing.T) {
+		d := monogo.NewDetector(nil, slog.Default(), g,
+			monogo.WithPath(tmpDir),
+			monogo.WithDiscover([]string{"cmd/*"}),
+		)
+
+		res, err := d.WhoUses(context.Background(), string(b), "test-project/pkg/pkgB")
+		require.NoError(t, err)
+		require.Equal(t, []monogo.WhoUsesEntrypointRes{
+			{Path: "cmd/app2", Chains: [][]string{{"test-project/cmd/app2", "test-project/pkg/pkgB"}}},
+			{Path: "cmd/app3", Chains: [][]string{{"test-project/cmd/app3", "test-project/pkg/pkgB"}}},
+		}, res.Entrypoints)
+	})
+}